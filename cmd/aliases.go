@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/StevenACoffman/jt/pkg/atlassian"
+
+	"github.com/spf13/cobra"
+)
+
+// aliasesCmd represents the config aliases command
+var aliasesCmd = &cobra.Command{
+	Use:   "aliases [ISSUE]",
+	Short: "List the status aliases in effect for an issue's project",
+	Long: `List the status aliases in effect for an issue's project.
+
+Aliases live in the "aliases" section of the config file, keyed by project key,
+with "*" for aliases that apply to every project:
+
+  "aliases": {
+    "*":    {"wip": "In Progress", "review": "Code Review", "ship": "Done"},
+    "TEAM": {"wip": "In Dev"}
+  }`,
+	Args: cobra.RangeArgs(0, 1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if jiraConfig == nil {
+			configure()
+		}
+		var issueKey string
+		if len(args) == 0 {
+			issueKey = getIssueFromGitBranch()
+		} else {
+			issueKey = args[0]
+		}
+		projectKey := atlassian.ProjectKey(issueKey)
+		if issueKey == "" {
+			projectKey = "*"
+		}
+
+		aliases := jiraConfig.Aliases.For(projectKey)
+		if len(aliases) == 0 {
+			fmt.Printf("No aliases configured for %s in %s\n", projectKey, cfgFile)
+			os.Exit(exitSuccess)
+		}
+		// anything the project doesn't set itself came from the "*" fallbacks
+		ownAliases := map[string]bool{}
+		for project, table := range jiraConfig.Aliases {
+			if strings.EqualFold(project, projectKey) {
+				for alias := range table {
+					ownAliases[strings.ToLower(alias)] = true
+				}
+			}
+		}
+		names := make([]string, 0, len(aliases))
+		for alias := range aliases {
+			names = append(names, alias)
+		}
+		sort.Strings(names)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ALIAS\tSTATUS\tFROM")
+		for _, alias := range names {
+			from := "*"
+			if ownAliases[alias] {
+				from = projectKey
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", alias, aliases[alias], from)
+		}
+		w.Flush()
+	},
+}
+
+func init() {
+	configCmd.AddCommand(aliasesCmd)
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/StevenACoffman/jt/pkg/atlassian"
+
+	"github.com/spf13/cobra"
+)
+
+// attachCmd represents the attach command
+var attachCmd = &cobra.Command{
+	Use:   "attach ISSUE FILE...",
+	Short: "Attach files to an issue",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if jiraConfig == nil {
+			configure()
+		}
+		issueKey := args[0]
+
+		failed := false
+		for _, path := range args[1:] {
+			attachments, err := atlassian.UploadAttachment(jiraClient, issueKey, path)
+			if err != nil {
+				fmt.Printf("Unable to attach %s to %s: %+v\n", path, issueKey, err)
+				failed = true
+				continue
+			}
+			for _, a := range attachments {
+				fmt.Printf("Attached %s (%s) to %s\n", a.Filename, humanSize(a.Size), issueKey)
+			}
+		}
+		if failed {
+			os.Exit(exitFail)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(attachCmd)
+}
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/StevenACoffman/jt/pkg/atlassian"
+	"github.com/StevenACoffman/jt/pkg/middleware"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/spf13/cobra"
+)
+
+var (
+	downloadAll bool
+	downloadDir string
+)
+
+// attachmentsCmd represents the attachments command
+var attachmentsCmd = &cobra.Command{
+	Use:   "attachments [ISSUE]",
+	Short: "List an issue's attachments",
+	Long: `List an issue's attachments with their size, who attached them and when.
+
+Use "jt attachments get" to download them.`,
+	Args: cobra.RangeArgs(0, 1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if jiraConfig == nil {
+			configure()
+		}
+		var issueKey string
+		if len(args) == 0 {
+			issueKey = getIssueFromGitBranch()
+		} else {
+			issueKey = args[0]
+		}
+		if issueKey == "" {
+			fmt.Println("unable to guess issue ID from branch")
+			os.Exit(exitFail)
+		}
+
+		attachments, err := issueAttachments(issueKey)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(exitFail)
+		}
+		if len(attachments) == 0 {
+			fmt.Printf("%s has no attachments\n", issueKey)
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tSIZE\tAUTHOR\tDATE")
+		for _, a := range attachments {
+			author := "Unknown"
+			if a.Author != nil {
+				author = a.Author.DisplayName
+			}
+			when, _ := formatDate(a.Created)
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", a.Filename, humanSize(a.Size), author, when)
+		}
+		w.Flush()
+	},
+}
+
+// attachmentsGetCmd represents the attachments get command
+var attachmentsGetCmd = &cobra.Command{
+	Use:   "get [ISSUE] [--all|NAME...]",
+	Short: "Download an issue's attachments",
+	Long: `Download an issue's attachments by name, or all of them with --all.
+
+Downloads are resumable: if one is interrupted, run the command again and it
+carries on where it left off. Files already downloaded are skipped.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if jiraConfig == nil {
+			configure()
+		}
+		var issueKey string
+		if len(args) > 0 && atlassian.IsIssueKey(args[0]) {
+			issueKey, args = args[0], args[1:]
+		} else {
+			issueKey = getIssueFromGitBranch()
+		}
+		if issueKey == "" {
+			fmt.Println("unable to guess issue ID from branch")
+			os.Exit(exitFail)
+		}
+		if downloadAll == (len(args) > 0) {
+			fmt.Println("name the attachments to download, or use --all")
+			os.Exit(exitFail)
+		}
+
+		attachments, err := issueAttachments(issueKey)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(exitFail)
+		}
+		if !downloadAll {
+			attachments, err = selectAttachments(attachments, args)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(exitFail)
+			}
+		}
+		if err := os.MkdirAll(downloadDir, 0o755); err != nil {
+			fmt.Println(err)
+			os.Exit(exitFail)
+		}
+
+		httpClient := middleware.NewBasicAuthHTTPClient(jiraConfig.User, jiraConfig.Token)
+		failed := false
+		used := make(map[string]bool)
+		for _, a := range attachments {
+			name := atlassian.AttachmentFilename(a)
+			// the same name can be attached more than once
+			if used[name] {
+				name = a.ID + "-" + name
+			}
+			used[name] = true
+			path := filepath.Join(downloadDir, name)
+			if err := atlassian.DownloadAttachment(httpClient, a, path); err != nil {
+				fmt.Println(err)
+				failed = true
+				continue
+			}
+			fmt.Printf("Downloaded %s (%s)\n", path, humanSize(a.Size))
+		}
+		if failed {
+			os.Exit(exitFail)
+		}
+	},
+}
+
+// issueAttachments gets the attachments on an issue
+func issueAttachments(issueKey string) ([]*jira.Attachment, error) {
+	issue, _, err := jiraClient.Issue.Get(issueKey, &jira.GetQueryOptions{Fields: "attachment"})
+	if err != nil {
+		return nil, fmt.Errorf("unable to get Issue %s: %+v", issueKey, err)
+	}
+	return issue.Fields.Attachments, nil
+}
+
+// selectAttachments picks out the attachments with the given names or IDs
+func selectAttachments(attachments []*jira.Attachment, names []string) ([]*jira.Attachment, error) {
+	var selected []*jira.Attachment
+	var missing []string
+	for _, name := range names {
+		found := false
+		for _, a := range attachments {
+			if a.Filename == name || a.ID == name {
+				selected = append(selected, a)
+				found = true
+			}
+		}
+		if !found {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("no attachment named %s", strings.Join(missing, ", "))
+	}
+	return selected, nil
+}
+
+// humanSize formats a number of bytes, like 1.5 MB
+func humanSize(size int) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	value, exp := float64(size)/unit, 0
+	for value >= unit && exp < 3 {
+		value /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", value, "KMGT"[exp])
+}
+
+func init() {
+	rootCmd.AddCommand(attachmentsCmd)
+	attachmentsCmd.AddCommand(attachmentsGetCmd)
+
+	flags := attachmentsGetCmd.Flags()
+	flags.BoolVar(&downloadAll, "all", false, "Download every attachment")
+	flags.StringVarP(&downloadDir, "output", "o", ".", "Directory to download into")
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/StevenACoffman/jt/pkg/atlassian"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+// bulkResult is what happened when we tried to move one issue
+type bulkResult struct {
+	issueKey string
+	err      error
+}
+
+// moveIssues transitions every issue named by issueKeys or found by jql to
+// statusName, a few at a time. Nobody can sensibly answer prompts for many
+// issues at once, so ambiguity and missing fields are failures, and
+// multi-step moves only happen with --yes.
+func moveIssues(statusName string, issueKeys []string, jql string, opts atlassian.TransitionOptions) []bulkResult {
+	opts.Choose = nil
+	opts.Prompt = nil
+	if !assumeYes {
+		opts.Confirm = nil
+	}
+
+	var issues []jira.Issue
+	if jql != "" {
+		found, err := atlassian.SearchIssues(jiraClient, jql)
+		if err != nil {
+			fmt.Printf("Unable to search for %s: %+v\n", jql, err)
+			os.Exit(exitFail)
+		}
+		issues = append(issues, found...)
+	}
+	for _, key := range issueKeys {
+		issues = append(issues, jira.Issue{Key: key})
+	}
+
+	results := make([]bulkResult, len(issues))
+	work := make(chan int)
+	var wg sync.WaitGroup
+	workers := concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				results[i] = moveIssue(issues[i], statusName, opts)
+			}
+		}()
+	}
+	for i := range issues {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+
+	return results
+}
+
+func moveIssue(issue jira.Issue, statusName string, opts atlassian.TransitionOptions) bulkResult {
+	result := bulkResult{issueKey: issue.Key}
+	if issue.Fields == nil {
+		fetched, _, err := jiraClient.Issue.Get(issue.Key, nil)
+		if err != nil {
+			result.err = fmt.Errorf("unable to get issue: %w", err)
+			return result
+		}
+		issue = *fetched
+	}
+	result.err = atlassian.MoveIssueToStatusByName(jiraClient, &issue, issue.Key, statusName, opts)
+	return result
+}
+
+// printBulkSummary prints a table of how each issue fared, and
+// reports whether they all succeeded.
+func printBulkSummary(results []bulkResult) bool {
+	ok := true
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "ISSUE\tRESULT\tDETAILS")
+	for _, r := range results {
+		if r.err != nil {
+			ok = false
+			detail := strings.Join(strings.Fields(r.err.Error()), " ")
+			fmt.Fprintf(w, "%s\t%s\t%s\n", r.issueKey, "failed", detail)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t\n", r.issueKey, "ok")
+	}
+	w.Flush()
+	return ok
+}
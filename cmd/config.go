@@ -47,6 +47,7 @@ func configure() {
 		fmt.Println(err)
 		os.Exit(exitFail)
 	}
+	jiraConfig.DryRun = dryRun
 	jiraClient = atlassian.GetJIRAClient(jiraConfig)
 	fmt.Println("Successfully wrote config to ", cfgFile)
 }
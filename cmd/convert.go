@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/StevenACoffman/jt/pkg/atlassian"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	convertFrom, convertTo string
+	linkIssues             bool
+)
+
+// convertCmd represents the convert command
+var convertCmd = &cobra.Command{
+	Use:   "convert",
+	Short: "Convert between Markdown, Jira markup and ADF",
+	Long: `Convert standard input between Github Markdown, Jira wiki markup and
+Atlassian Document Format (ADF) JSON, writing the result to standard
+output. For instance, to paste a PR description into Jira:
+
+  jt convert --from md --to jira < description.md
+
+When converting to Markdown, --link-issues links issue keys to the
+issues on your Jira site, like wti does. Unless "issue_key_pattern" is
+set in the config file, each key outside it costs a request to Jira to
+check that it is an issue.`,
+	Args: cobra.NoArgs,
+	// converting doesn't need Jira, so only read the config to link issues
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if linkIssues {
+			initConfig()
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		input, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(exitFail)
+		}
+		doc, err := parseFormat(convertFrom, string(input))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(exitFail)
+		}
+		output, err := renderFormat(convertTo, doc)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(exitFail)
+		}
+		fmt.Println(output)
+	},
+}
+
+func parseFormat(format, input string) (*atlassian.Node, error) {
+	switch format {
+	case "md", "markdown":
+		return atlassian.ParseMarkdown(input), nil
+	case "jira":
+		return atlassian.ParseJira(input), nil
+	case "adf":
+		var doc atlassian.ADFNode
+		if err := json.Unmarshal([]byte(input), &doc); err != nil {
+			return nil, fmt.Errorf("unable to parse ADF: %v", err)
+		}
+		return atlassian.DecodeADF(&doc), nil
+	default:
+		return nil, fmt.Errorf("unknown format %q, expected md, jira or adf", format)
+	}
+}
+
+func renderFormat(format string, doc *atlassian.Node) (string, error) {
+	switch format {
+	case "md", "markdown":
+		linker, err := convertLinker()
+		if err != nil {
+			return "", err
+		}
+		return atlassian.RenderMarkdown(doc, atlassian.MarkdownOptions{Issues: linker}), nil
+	case "jira":
+		return atlassian.RenderJira(doc), nil
+	case "adf":
+		data, err := json.MarshalIndent(atlassian.EncodeADF(doc), "", "  ")
+		return string(data), err
+	default:
+		return "", fmt.Errorf("unknown format %q, expected md, jira or adf", format)
+	}
+}
+
+// convertLinker links issue keys to the configured Jira site if
+// --link-issues asks for it
+func convertLinker() (*atlassian.IssueLinker, error) {
+	if !linkIssues {
+		return nil, nil
+	}
+	if jiraConfig == nil || jiraConfig.Host == "" {
+		return nil, fmt.Errorf("--link-issues needs a Jira site, run jt config to set one")
+	}
+	linker, err := atlassian.NewIssueLinker(jiraConfig.Host, jiraConfig.IssueKeyPattern)
+	if err != nil {
+		return nil, err
+	}
+	linker.Lookup = atlassian.IssueLookup(atlassian.GetQuietJIRAClient(jiraConfig))
+	return linker, nil
+}
+
+func init() {
+	rootCmd.AddCommand(convertCmd)
+
+	flags := convertCmd.Flags()
+	flags.StringVar(&convertFrom, "from", "md", "Format to convert from: md, jira or adf")
+	flags.StringVar(&convertTo, "to", "jira", "Format to convert to: md, jira or adf")
+	flags.BoolVar(&linkIssues, "link-issues", false, "Link issue keys to your Jira site when converting to md")
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/StevenACoffman/jt/pkg/atlassian"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/spf13/cobra"
+)
+
+var (
+	historyFields []string
+	historyOutput string
+)
+
+// historyCmd represents the history command
+var historyCmd = &cobra.Command{
+	Use:   "history [ISSUE]",
+	Short: "Show who changed an issue and when",
+	Long: `Show who changed an issue and when: status changes, reassignments and
+field edits, oldest first, followed by how long the issue has spent in each status.
+
+--field status shows only status changes, and can be repeated.`,
+	Args: cobra.RangeArgs(0, 1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if jiraConfig == nil {
+			configure()
+		}
+		var issueKey string
+		if len(args) == 0 {
+			issueKey = getIssueFromGitBranch()
+		} else {
+			issueKey = args[0]
+		}
+		if issueKey == "" {
+			fmt.Println("unable to guess issue ID from branch")
+			os.Exit(exitFail)
+		}
+
+		issue, err := atlassian.GetIssueHistory(jiraClient, issueKey)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(exitFail)
+		}
+		all := atlassian.HistoryEvents(issue)
+		listing := historyListing{
+			Events:       atlassian.FilterHistory(all, historyFields),
+			TimeInStatus: atlassian.TimeInStatus(issue, all, time.Now()),
+		}
+
+		switch historyOutput {
+		case "json":
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(listing); err != nil {
+				fmt.Println(err)
+				os.Exit(exitFail)
+			}
+		case "", "table":
+			printHistory(listing)
+		default:
+			fmt.Printf("unknown output format %q, expected table or json\n", historyOutput)
+			os.Exit(exitFail)
+		}
+	},
+}
+
+// historyListing is everything the history command reports
+type historyListing struct {
+	Events       []atlassian.HistoryEvent `json:"events"`
+	TimeInStatus []atlassian.StatusTime   `json:"timeInStatus"`
+}
+
+func printHistory(listing historyListing) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "WHEN\tAUTHOR\tFIELD\tFROM\tTO")
+	for _, e := range listing.Events {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", e.Time.Local().Format("2006-01-02 15:04"),
+			e.Author, e.Field, historyValue(e.From), historyValue(e.To))
+	}
+	w.Flush()
+
+	fmt.Println()
+	w = tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "STATUS\tTIME")
+	for _, s := range listing.TimeInStatus {
+		fmt.Fprintf(w, "%s\t%s\n", s.Status, atlassian.FormatDuration(s.Duration))
+	}
+	w.Flush()
+}
+
+// historyValue keeps long values, like edited descriptions, to one short line
+func historyValue(value string) string {
+	value = strings.Join(strings.Fields(value), " ")
+	if value == "" {
+		return "-"
+	}
+	if runes := []rune(value); len(runes) > 40 {
+		return string(runes[:39]) + "…"
+	}
+	return value
+}
+
+// historyMarkdown renders the issue's changelog as a "## History" section
+func historyMarkdown(issue *jira.Issue) string {
+	all := atlassian.HistoryEvents(issue)
+	var b strings.Builder
+	b.WriteString("\n## History\n\n")
+	events := atlassian.FilterHistory(all, historyFields)
+	if len(events) == 0 {
+		b.WriteString("No changes.\n")
+	}
+	for _, e := range events {
+		fmt.Fprintf(&b, "- %s **%s** changed %s from %s to %s\n",
+			e.Time.Local().Format("2006-01-02 15:04"), e.Author, e.Field,
+			historyValue(e.From), historyValue(e.To))
+	}
+	b.WriteString("\n| Status | Time |\n|---|---|\n")
+	for _, s := range atlassian.TimeInStatus(issue, all, time.Now()) {
+		fmt.Fprintf(&b, "| %s | %s |\n", s.Status, atlassian.FormatDuration(s.Duration))
+	}
+	return b.String()
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+
+	flags := historyCmd.Flags()
+	flags.StringArrayVar(&historyFields, "field", nil, "Only show changes to this field (repeatable)")
+	flags.StringVarP(&historyOutput, "output", "o", "table", "Output format: table or json")
+}
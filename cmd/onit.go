@@ -31,13 +31,19 @@ var onitCmd = &cobra.Command{
 			os.Exit(exitFail)
 		}
 
-		err := atlassian.MoveIssueToStatusByName(jiraClient, issue, issueKey, "In Progress")
+		// boards name this differently, so prefer the project's "wip" alias if it has one
+		statusName := "In Progress"
+		if _, ok := jiraConfig.Aliases.For(issue.Fields.Project.Key)["wip"]; ok {
+			statusName = "wip"
+		}
+		err := atlassian.MoveIssueToStatusByName(
+			jiraClient, issue, issueKey, statusName, transitionOptions(issueKey))
 		if err != nil {
 			fmt.Println(err)
 			os.Exit(exitFail)
 		}
 
-		err = atlassian.AssignIssueToSelf(jiraClient, issue, issueKey)
+		err = atlassian.AssignIssueToSelf(jiraClient, issue, issueKey, journal(), dryRun)
 		if err != nil {
 			fmt.Println(err)
 			os.Exit(exitFail)
@@ -49,6 +55,7 @@ var onitCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(onitCmd)
+	addTransitionFlags(onitCmd)
 
 	// Here you will define your flags and configuration settings.
 
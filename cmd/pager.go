@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/containerd/console"
+)
+
+// terminalSize returns the width and height of the terminal on stdout,
+// guessing 80x24 if it can't tell.
+func terminalSize() (width, height int) {
+	c, err := console.ConsoleFromFile(os.Stdout)
+	if err != nil {
+		return 80, 24
+	}
+	size, err := c.Size()
+	if err != nil || size.Width == 0 {
+		return 80, 24
+	}
+	return int(size.Width), int(size.Height)
+}
+
+// page shows text through $PAGER if it is too tall for the terminal,
+// falling back to printing it if there is no pager to be had.
+func page(text string) {
+	_, height := terminalSize()
+	if strings.Count(text, "\n") < height-1 {
+		fmt.Print(text)
+		return
+	}
+	pager := os.Getenv("PAGER")
+	if pager == "" {
+		pager = "less -R"
+	}
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", pager)
+	} else {
+		cmd = exec.Command("sh", "-c", pager)
+	}
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Print(text)
+	}
+}
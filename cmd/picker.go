@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/StevenACoffman/jt/pkg/atlassian"
+
+	"github.com/andygrunwald/go-jira"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-isatty"
+)
+
+// pickerItem is one selectable row in a picker
+type pickerItem struct {
+	title string
+	desc  string
+}
+
+type pickerModel struct {
+	header string
+	items  []pickerItem
+	cursor int
+	chosen int
+}
+
+// pick shows the items as a list and returns the index of the one
+// the user selected, or -1 if they quit without choosing.
+func pick(header string, items []pickerItem) (int, error) {
+	m := &pickerModel{header: header, items: items, chosen: -1}
+	if err := tea.NewProgram(m).Start(); err != nil {
+		return -1, fmt.Errorf("could not start program: %w", err)
+	}
+	return m.chosen, nil
+}
+
+func (m *pickerModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *pickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "ctrl+c", "esc", "q":
+			return m, tea.Quit
+		case "up", "k", "shift+tab":
+			m.cursor--
+			if m.cursor < 0 {
+				m.cursor = len(m.items) - 1
+			}
+		case "down", "j", "tab":
+			m.cursor++
+			if m.cursor >= len(m.items) {
+				m.cursor = 0
+			}
+		case "enter":
+			m.chosen = m.cursor
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+func (m *pickerModel) View() string {
+	var b strings.Builder
+	b.WriteString(m.header + "\n\n")
+	for i, item := range m.items {
+		if i == m.cursor {
+			b.WriteString(focusedStyle.Render("> " + item.title))
+		} else {
+			b.WriteString(noStyle.Render("  " + item.title))
+		}
+		if item.desc != "" {
+			b.WriteString(" " + blurredStyle.Render(item.desc))
+		}
+		b.WriteRune('\n')
+	}
+	b.WriteString("\n" + helpStyle.Render("↑/↓ to move, enter to choose, esc to cancel") + "\n")
+	return b.String()
+}
+
+// isInteractive reports whether we can prompt the user on this terminal
+func isInteractive() bool {
+	return isatty.IsTerminal(os.Stdin.Fd()) && isatty.IsTerminal(os.Stdout.Fd())
+}
+
+// transitionChooser returns a picker for ambiguous transitions,
+// or nil if there is nobody around to answer it.
+func transitionChooser(issueKey string) atlassian.TransitionChooser {
+	if !isInteractive() {
+		return nil
+	}
+	return func(candidates []jira.Transition) (*jira.Transition, error) {
+		items := make([]pickerItem, len(candidates))
+		for i, t := range candidates {
+			items[i] = pickerItem{
+				title: t.To.Name,
+				desc:  fmt.Sprintf("(%s) via %q", t.To.StatusCategory.Name, t.Name),
+			}
+		}
+		i, err := pick(fmt.Sprintf("Move %s to which status?", issueKey), items)
+		if err != nil || i < 0 {
+			return nil, err
+		}
+		return &candidates[i], nil
+	}
+}
+
+// confirm asks a yes or no question, defaulting to no
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// transitionConfirmer returns a prompt for multi-step moves. With --yes we
+// don't ask, and if nobody is around to answer, we don't move.
+func transitionConfirmer() atlassian.TransitionConfirmer {
+	if assumeYes {
+		return func(string, []string) bool { return true }
+	}
+	if !isInteractive() {
+		return nil
+	}
+	return func(issueKey string, path []string) bool {
+		return confirm(fmt.Sprintf("Move %s through %s?", issueKey, strings.Join(path, " -> ")))
+	}
+}
+
+// fieldPrompter asks for required transition fields, offering a list when
+// the field has a fixed set of values.
+func fieldPrompter() atlassian.FieldPrompter {
+	if !isInteractive() {
+		return nil
+	}
+	return func(field atlassian.TransitionField) (string, error) {
+		if len(field.AllowedValues) > 0 {
+			items := make([]pickerItem, len(field.AllowedValues))
+			for i, v := range field.AllowedValues {
+				items[i] = pickerItem{title: v.String()}
+			}
+			i, err := pick(fmt.Sprintf("%s is required:", field.Name), items)
+			if err != nil || i < 0 {
+				return "", err
+			}
+			return field.AllowedValues[i].ID, nil
+		}
+		fmt.Printf("%s (required): ", field.Name)
+		answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(answer), nil
+	}
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -22,48 +23,87 @@ const (
 )
 
 var (
-	cfgFile    string
-	jiraClient *jira.Client
-	jiraConfig *atlassian.Config
+	cfgFile     string
+	dryRun      bool
+	maxHops     int
+	assumeYes   bool
+	resolution  string
+	comment     string
+	fieldArgs   []string
+	jql         string
+	concurrency int
+	jiraClient  *jira.Client
+	jiraConfig  *atlassian.Config
 )
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "jt",
 	Short: "jt - JIRA Issue Tool",
-	Long:  `jt is a CLI tool for viewing and manipulating JIRA issues.`,
-	Args:  cobra.RangeArgs(1, 2),
+	Long: `jt is a CLI tool for viewing and manipulating JIRA issues.
+
+jt [new state] [issue number...] moves issues to a new state.
+With several issues, or --jql, they are all moved and summarized.`,
+	Args: cobra.ArbitraryArgs,
+	// every command reads the config file first, unless it says otherwise
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		initConfig()
+	},
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	Run: func(cmd *cobra.Command, args []string) {
 		if jiraConfig == nil {
 			configure()
 		}
-		if len(args) == 0 {
-			fmt.Println(
-				"You need to pass a desired jira status argument (and maybe a jira issue like TEAM-1234)",
-			)
+		var issueKey, statusName string
+		if len(args) > 0 {
+			statusName = args[0]
+		}
+		fields, err := parseFieldArgs(fieldArgs)
+		if err != nil {
+			fmt.Println(err)
 			os.Exit(exitFail)
 		}
-		var issueKey string
-		statusName := args[0]
+		if resolution != "" {
+			fields["resolution"] = resolution
+		}
+
+		if jql != "" || len(args) > 2 {
+			if statusName == "" {
+				fmt.Println("You need to pass a desired jira status to move several issues")
+				os.Exit(exitFail)
+			}
+			opts := transitionOptions("")
+			opts.Fields = fields
+			opts.Comment = comment
+			results := moveIssues(statusName, args[1:], jql, opts)
+			if !printBulkSummary(results) {
+				os.Exit(exitFail)
+			}
+			os.Exit(exitSuccess)
+		}
+
 		if len(args) > 1 {
 			issueKey = args[1]
 		} else {
 			issueKey = getIssueFromGitBranch()
 		}
-		if issueKey != "" {
-			fmt.Printf("unable to guess issue ID from branch")
+		if issueKey == "" {
+			fmt.Println("unable to guess issue ID from branch")
 			os.Exit(exitFail)
 		}
 
-		issue, _, issueErr := jiraClient.Issue.Get(issueKey, nil)
-		if issueErr != nil {
-			fmt.Printf("Unable to get Issue %s: %+v", issueKey, issueErr)
+		issue, _, err := jiraClient.Issue.Get(issueKey, nil)
+		if err != nil {
+			fmt.Printf("Unable to get Issue %s: %+v", issueKey, err)
 			os.Exit(exitFail)
 		}
 
-		err := atlassian.MoveIssueToStatusByName(jiraClient, issue, issueKey, statusName)
+		opts := transitionOptions(issueKey)
+		opts.Fields = fields
+		opts.Comment = comment
+
+		err = atlassian.MoveIssueToStatusByName(jiraClient, issue, issueKey, statusName, opts)
 		if err != nil {
 			fmt.Println(err)
 			os.Exit(exitFail)
@@ -82,14 +122,67 @@ func Execute() {
 }
 
 func init() {
-	cobra.OnInitialize(initConfig)
-
 	// Here you will define your flags and configuration settings.
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
 
 	rootCmd.PersistentFlags().
 		StringVar(&cfgFile, "config", "", "config file (default is $HOME/.config/jira)")
+	rootCmd.PersistentFlags().
+		BoolVar(&dryRun, "dry-run", false, "show the changes that would be made to JIRA without making them")
+	addTransitionFlags(rootCmd)
+
+	flags := rootCmd.Flags()
+	flags.StringVar(&resolution, "resolution", "", "Resolution to set, like Done or Won't Do")
+	flags.StringVar(&comment, "comment", "", "Comment to add along with the transition")
+	flags.StringArrayVar(&fieldArgs, "field", nil,
+		"Field to set on the transition screen as name=value (repeatable)")
+	flags.StringVar(&jql, "jql", "", "Move every issue matching this JQL query")
+	flags.IntVar(&concurrency, "concurrency", 4, "How many issues to move at once")
+}
+
+// addTransitionFlags adds the flags shared by commands that transition issues
+func addTransitionFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.IntVar(&maxHops, "max-hops", 4,
+		"Most transitions to chain together to reach a status that is not directly reachable")
+	flags.BoolVarP(&assumeYes, "yes", "y", false, "Do not ask before multi-step moves")
+}
+
+// transitionOptions builds the options for moving an issue from the flags
+func transitionOptions(issueKey string) atlassian.TransitionOptions {
+	return atlassian.TransitionOptions{
+		Choose:  transitionChooser(issueKey),
+		MaxHops: maxHops,
+		Confirm: transitionConfirmer(),
+		Prompt:  fieldPrompter(),
+		Aliases: jiraConfig.Aliases,
+		Journal: journal(),
+		Hooks:   jiraConfig.Hooks,
+		DryRun:  dryRun,
+	}
+}
+
+// journal returns where we record changes so they can be undone.
+// Dry runs don't change anything, so they have nothing to record.
+func journal() *atlassian.Journal {
+	if dryRun {
+		return nil
+	}
+	return atlassian.NewJournal(cfgFile + ".journal")
+}
+
+// parseFieldArgs turns repeated name=value flags into a map
+func parseFieldArgs(args []string) (map[string]string, error) {
+	fields := make(map[string]string, len(args))
+	for _, arg := range args {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("--field %q should look like name=value", arg)
+		}
+		fields[strings.TrimSpace(parts[0])] = parts[1]
+	}
+	return fields, nil
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -117,14 +210,33 @@ func initConfig() {
 
 	// If a config file is found, read it in.
 	if err := v.ReadInConfig(); err != nil {
-		fmt.Println("Unable to read config using config file:", v.ConfigFileUsed())
+		fmt.Fprintln(os.Stderr, "Unable to read config using config file:", v.ConfigFileUsed())
 		return
 	}
 
+	var aliases atlassian.StatusAliases
+	if err := v.UnmarshalKey("aliases", &aliases); err != nil {
+		fmt.Fprintln(os.Stderr, "Unable to read aliases from config file:", err)
+	}
+	var hooks atlassian.Hooks
+	if err := v.UnmarshalKey("hooks", &hooks); err != nil {
+		fmt.Fprintln(os.Stderr, "Unable to read hooks from config file:", err)
+	}
+	var fields atlassian.FieldNames
+	if err := v.UnmarshalKey("fields", &fields); err != nil {
+		fmt.Fprintln(os.Stderr, "Unable to read fields from config file:", err)
+	}
+
 	jiraConfig = &atlassian.Config{
-		Token: getEnv("ATLASSIAN_API_TOKEN", v.GetString("token")),
-		User:  getEnv("ATLASSIAN_API_USER", v.GetString("user")),
-		Host:  getEnv("ATLASSIAN_HOST", v.GetString("host")),
+		Token:           getEnv("ATLASSIAN_API_TOKEN", v.GetString("token")),
+		User:            getEnv("ATLASSIAN_API_USER", v.GetString("user")),
+		Host:            getEnv("ATLASSIAN_HOST", v.GetString("host")),
+		Aliases:         aliases,
+		Hooks:           hooks,
+		Fields:          fields,
+		DryRun:          dryRun,
+		APIVersion:      getEnv("ATLASSIAN_API_VERSION", v.GetString("api_version")),
+		IssueKeyPattern: v.GetString("issue_key_pattern"),
 	}
 	jiraClient = atlassian.GetJIRAClient(jiraConfig)
 }
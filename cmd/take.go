@@ -30,7 +30,7 @@ var takeCmd = &cobra.Command{
 			fmt.Printf("Unable to get Issue %s: %+v", issueKey, issueErr)
 			os.Exit(exitFail)
 		}
-		err := atlassian.AssignIssueToSelf(jiraClient, issue, issueKey)
+		err := atlassian.AssignIssueToSelf(jiraClient, issue, issueKey, journal(), dryRun)
 		if err != nil {
 			fmt.Println(err)
 			os.Exit(exitFail)
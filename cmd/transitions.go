@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/StevenACoffman/jt/pkg/atlassian"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/spf13/cobra"
+)
+
+var transitionsOutput, transitionsTry string
+
+// transitionsCmd represents the transitions command
+var transitionsCmd = &cobra.Command{
+	Use:   "transitions [ISSUE]",
+	Short: "List the transitions available for an issue",
+	Long: `List the transitions available for an issue, with their target status,
+status category, and whether they show a screen or require any fields.
+
+Use --try to see which transition jt would pick for a status you might type.`,
+	Args: cobra.RangeArgs(0, 1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if jiraConfig == nil {
+			configure()
+		}
+		var issueKey string
+		if len(args) == 0 {
+			issueKey = getIssueFromGitBranch()
+		} else {
+			issueKey = args[0]
+		}
+		if issueKey == "" {
+			fmt.Println("unable to guess issue ID from branch")
+			os.Exit(exitFail)
+		}
+
+		details, err := atlassian.GetTransitionDetails(jiraClient, issueKey, "")
+		if err != nil {
+			fmt.Printf("Unable to get transitions for %s: %+v\n", issueKey, err)
+			os.Exit(exitFail)
+		}
+
+		listing := transitionListing{Transitions: details}
+		if transitionsTry != "" {
+			listing.Try = tryTransition(issueKey, details, transitionsTry)
+		}
+
+		switch transitionsOutput {
+		case "json":
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(listing); err != nil {
+				fmt.Println(err)
+				os.Exit(exitFail)
+			}
+		case "", "table":
+			printTransitions(listing)
+		default:
+			fmt.Printf("unknown output format %q, expected table or json\n", transitionsOutput)
+			os.Exit(exitFail)
+		}
+	},
+}
+
+// transitionListing is everything the transitions command reports
+type transitionListing struct {
+	Transitions []atlassian.TransitionDetails `json:"transitions"`
+	Try         *tryResult                    `json:"try,omitempty"`
+}
+
+// tryResult is what the matcher made of a --try input
+type tryResult struct {
+	Input string `json:"input"`
+	// Status is the input after resolving aliases
+	Status     string         `json:"status"`
+	PickID     string         `json:"pick,omitempty"`
+	Candidates []tryCandidate `json:"candidates"`
+	// ranked are the candidates as the matcher scored them
+	ranked []atlassian.ScoredTransition
+}
+
+type tryCandidate struct {
+	ID     string  `json:"id"`
+	Status string  `json:"status"`
+	Score  float64 `json:"score"`
+}
+
+func tryTransition(issueKey string, details []atlassian.TransitionDetails, input string) *tryResult {
+	statusName := jiraConfig.Aliases.Resolve(atlassian.ProjectKey(issueKey), input)
+	possible := make([]jira.Transition, len(details))
+	for i, d := range details {
+		possible[i] = jira.Transition{ID: d.ID, Name: d.Name, To: d.To}
+	}
+
+	result := &tryResult{Input: input, Status: statusName}
+	result.ranked = atlassian.RankTransitions(possible, statusName)
+	for _, c := range result.ranked {
+		result.Candidates = append(result.Candidates,
+			tryCandidate{ID: c.ID, Status: c.To.Name, Score: c.Score})
+	}
+	if matches := atlassian.MatchTransitions(possible, statusName); len(matches) == 1 {
+		result.PickID = matches[0].ID
+	}
+	return result
+}
+
+func printTransitions(listing transitionListing) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tTO STATUS\tCATEGORY\tSCREEN\tREQUIRED FIELDS")
+	for _, t := range listing.Transitions {
+		var required []string
+		for _, f := range t.Fields {
+			if f.Required && !f.HasDefaultValue {
+				required = append(required, f.Name)
+			}
+		}
+		sort.Strings(required)
+		screen := "no"
+		if t.HasScreen {
+			screen = "yes"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			t.ID, t.Name, t.To.Name, t.To.StatusCategory.Name, screen, strings.Join(required, ", "))
+	}
+	w.Flush()
+
+	try := listing.Try
+	if try == nil {
+		return
+	}
+	fmt.Println()
+	input := fmt.Sprintf("%q", try.Input)
+	if !strings.EqualFold(try.Input, try.Status) {
+		input += fmt.Sprintf(" (alias for %q)", try.Status)
+	}
+	for _, c := range try.Candidates {
+		if c.ID == try.PickID {
+			fmt.Printf("%s would pick transition %s to %s (score %.2f)\n", input, c.ID, c.Status, c.Score)
+			return
+		}
+	}
+	fmt.Printf("%s does not clearly match one transition, closest: %s\n",
+		input, atlassian.DidYouMean(try.ranked))
+}
+
+func init() {
+	rootCmd.AddCommand(transitionsCmd)
+
+	flags := transitionsCmd.Flags()
+	flags.StringVarP(&transitionsOutput, "output", "o", "table", "Output format: table or json")
+	flags.StringVar(&transitionsTry, "try", "", "Show which transition jt would pick for this status")
+}
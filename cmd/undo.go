@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/StevenACoffman/jt/pkg/atlassian"
+
+	"github.com/spf13/cobra"
+)
+
+// undoCmd represents the undo command
+var undoCmd = &cobra.Command{
+	Use:   "undo [n]",
+	Short: "Undo the last n changes jt made (default 1)",
+	Long: `Undo the last n changes jt made, newest first (default 1).
+
+Every status change and assignment jt makes is recorded in a journal next to
+your config file. Undo moves issues back to their previous status, through
+several transitions if it has to, and gives them back to their previous assignee.
+It stops at the first change it can't undo, such as an issue that has been
+changed by someone else since.`,
+	Args: cobra.RangeArgs(0, 1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if jiraConfig == nil {
+			configure()
+		}
+		n := 1
+		if len(args) > 0 {
+			var err error
+			n, err = strconv.Atoi(args[0])
+			if err != nil || n < 1 {
+				fmt.Printf("%q is not a number of changes to undo\n", args[0])
+				os.Exit(exitFail)
+			}
+		}
+
+		j := atlassian.NewJournal(cfgFile + ".journal")
+		entries, err := j.Entries()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(exitFail)
+		}
+		if len(entries) == 0 {
+			fmt.Println("Nothing to undo")
+			os.Exit(exitSuccess)
+		}
+		if n > len(entries) {
+			n = len(entries)
+		}
+
+		undone := 0
+		for i := len(entries) - 1; i >= len(entries)-n; i-- {
+			entry := entries[i]
+			fmt.Println("Undoing:", entry)
+			if err := undoEntry(entry); err != nil {
+				fmt.Println(err)
+				break
+			}
+			undone++
+		}
+		if !dryRun {
+			if err := j.DropLast(undone); err != nil {
+				fmt.Println("Unable to update journal:", err)
+				os.Exit(exitFail)
+			}
+		}
+		if undone < n {
+			os.Exit(exitFail)
+		}
+		os.Exit(exitSuccess)
+	},
+}
+
+// undoEntry reverses one journaled change, as long as the issue
+// is still how we left it.
+func undoEntry(entry atlassian.JournalEntry) error {
+	issue, _, err := jiraClient.Issue.Get(entry.IssueKey, nil)
+	if err != nil {
+		return fmt.Errorf("unable to get Issue %s: %+v", entry.IssueKey, err)
+	}
+
+	switch entry.Action {
+	case atlassian.JournalTransition:
+		if issue.Fields.Status.Name != entry.ToStatus {
+			return fmt.Errorf("%s is now %s, not %s, so not moving it back",
+				entry.IssueKey, issue.Fields.Status.Name, entry.ToStatus)
+		}
+		opts := atlassian.TransitionOptions{
+			MaxHops: maxHops,
+			Confirm: func(string, []string) bool { return true },
+			Prompt:  fieldPrompter(),
+		}
+		return atlassian.MoveIssueToStatusByName(
+			jiraClient, issue, entry.IssueKey, entry.FromStatus, opts)
+	case atlassian.JournalAssign:
+		current := ""
+		if issue.Fields.Assignee != nil {
+			current = issue.Fields.Assignee.AccountID
+		}
+		if current != entry.ToAssignee {
+			return fmt.Errorf("%s has been reassigned since, so not changing it back", entry.IssueKey)
+		}
+		if err := atlassian.SetAssignee(jiraClient, entry.IssueKey, entry.FromAssignee); err != nil {
+			return fmt.Errorf("unable to reassign %s: %+v", entry.IssueKey, err)
+		}
+		if entry.FromAssignee == "" {
+			fmt.Printf("Unassigned %s\n", entry.IssueKey)
+		} else {
+			fmt.Printf("Re-Assigned %s to %s\n", entry.IssueKey, entry.FromAssignee)
+		}
+		return nil
+	default:
+		return fmt.Errorf("don't know how to undo %q", entry.Action)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(undoCmd)
+	undoCmd.Flags().IntVar(&maxHops, "max-hops", 4,
+		"Most transitions to chain together to get back to the previous status")
+}
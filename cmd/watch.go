@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/StevenACoffman/jt/pkg/atlassian"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/spf13/cobra"
+)
+
+var (
+	watchInterval time.Duration
+	watchUntil    string
+)
+
+// maxWatchBackoff is the longest watch-issue waits between polls when Jira is failing
+const maxWatchBackoff = 5 * time.Minute
+
+// watchCmd represents the watch-issue command
+var watchCmd = &cobra.Command{
+	Use:   "watch-issue [ISSUE]",
+	Short: "Print changes to an issue as they happen",
+	Long: `Check an issue every --interval and print what changed since the last check:
+its status, assignee, new comments (as Github Markdown) and any other fields.
+
+If Jira can't be reached, it waits longer between checks until it can.
+With --until, it exits once the issue reaches that status, so a script can
+wait on an issue being Done.`,
+	Args: cobra.RangeArgs(0, 1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if jiraConfig == nil {
+			configure()
+		}
+		var issueKey string
+		if len(args) == 0 {
+			issueKey = getIssueFromGitBranch()
+		} else {
+			issueKey = args[0]
+		}
+		if issueKey == "" {
+			fmt.Println("unable to guess issue ID from branch")
+			os.Exit(exitFail)
+		}
+		if watchInterval <= 0 {
+			fmt.Println("--interval must be more than zero")
+			os.Exit(exitFail)
+		}
+		until := ""
+		if watchUntil != "" {
+			until = jiraConfig.Aliases.Resolve(atlassian.ProjectKey(issueKey), watchUntil)
+		}
+
+		last, _, err := fetchWatched(issueKey)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(exitFail)
+		}
+		fmt.Printf("Watching %s (%s, assigned to %s), checking every %s\n", issueKey,
+			last.Fields.Status.Name, atlassian.DisplayJiraUser(last.Fields.Assignee), watchInterval)
+		if reachedStatus(last, until) {
+			os.Exit(exitSuccess)
+		}
+
+		wait := watchInterval
+		for {
+			time.Sleep(wait)
+			current, docs, err := fetchWatched(issueKey)
+			if err != nil {
+				wait *= 2
+				if wait > maxWatchBackoff {
+					wait = maxWatchBackoff
+				}
+				fmt.Printf("%s %v, trying again in %s\n", watchTime(), err, wait)
+				continue
+			}
+			wait = watchInterval
+
+			if err := printIssueChanges(last, current, docs); err != nil {
+				fmt.Println(err)
+			}
+			last = current
+			if reachedStatus(current, until) {
+				os.Exit(exitSuccess)
+			}
+		}
+	},
+}
+
+// fetchWatched gets the issue along with its field names, to describe
+// changes, and its rich text as documents when read through version 3
+func fetchWatched(issueKey string) (*jira.Issue, *atlassian.IssueDocuments, error) {
+	options := &jira.GetQueryOptions{Expand: "names"}
+	var issue *jira.Issue
+	var docs *atlassian.IssueDocuments
+	var err error
+	if jiraConfig.UsesADF() {
+		issue, docs, err = atlassian.GetIssueV3(jiraClient, issueKey, options)
+	} else {
+		issue, _, err = jiraClient.Issue.Get(issueKey, options)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to get Issue %s: %+v", issueKey, err)
+	}
+	return issue, docs, nil
+}
+
+func reachedStatus(issue *jira.Issue, status string) bool {
+	if status == "" || issue.Fields.Status == nil {
+		return false
+	}
+	if !strings.EqualFold(issue.Fields.Status.Name, status) {
+		return false
+	}
+	fmt.Printf("%s %s reached %s\n", watchTime(), issue.Key, issue.Fields.Status.Name)
+	return true
+}
+
+// printIssueChanges prints what changed between two fetches of an issue,
+// showing new comments from docs when we have them
+func printIssueChanges(before, after *jira.Issue, docs *atlassian.IssueDocuments) error {
+	changes, err := atlassian.DiffIssues(before, after)
+	if err != nil {
+		return err
+	}
+	for _, c := range changes {
+		if c.Long {
+			fmt.Printf("%s %s changed\n", watchTime(), c.Field)
+			continue
+		}
+		fmt.Printf("%s %s: %s → %s\n", watchTime(), c.Field, c.From, c.To)
+	}
+	for _, c := range atlassian.NewComments(before, after) {
+		body := atlassian.JiraMarkupToGithubMarkdown(jiraClient, c.Body)
+		if doc := docs.Comment(c.ID); doc != nil {
+			body = atlassian.IssueDocumentToGithubMarkdown(jiraClient, doc, atlassian.MarkdownOptions{})
+		}
+		fmt.Printf("%s New comment from %s:\n\n%s\n\n", watchTime(),
+			atlassian.DisplayJiraUser(&c.Author), body)
+	}
+	return nil
+}
+
+func watchTime() string {
+	return time.Now().Format("[15:04:05]")
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+
+	flags := watchCmd.Flags()
+	flags.DurationVar(&watchInterval, "interval", 30*time.Second, "How often to check the issue")
+	flags.StringVar(&watchUntil, "until", "", "Exit once the issue reaches this status")
+}
@@ -1,25 +1,98 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
 
 	"github.com/StevenACoffman/jt/pkg/atlassian"
+	"github.com/StevenACoffman/jt/pkg/colors"
+	"github.com/StevenACoffman/jt/pkg/middleware"
+	"github.com/StevenACoffman/jt/pkg/render"
 
+	"github.com/andygrunwald/go-jira"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
 )
 
-var omitTitle, omitDescription bool
+var (
+	omitTitle, omitDescription bool
+	wtiOutput, wtiTemplate     string
+	showComments               bool
+	lastComments               int
+	rawOutput                  bool
+	showTree                   bool
+	treeDepth                  int
+	fieldList                  string
+	showHistory                bool
+	downloadImages             string
+	linkSummaries              string
+	// shownFields are the extra fields to show, resolved from --fields or the config
+	shownFields []jira.Field
+	// issueImages resolves the images in the issue's markup, see imageResolver
+	issueImages func(string) string
+	// issueLinks links the issue keys in the issue's markup, see issueLinker
+	issueLinks *atlassian.IssueLinker
+	// issueDocs is the issue's rich text as documents, when read through
+	// version 3 of the API
+	issueDocs *atlassian.IssueDocuments
+)
 
 // wtiCmd represents the wti command
 var wtiCmd = &cobra.Command{
 	Use:   "wti",
 	Short: "What The Issue? - View an issue",
-	Long:  `What The Issue? Will View an issue.`,
-	Args:  cobra.RangeArgs(0, 1),
+	Long: `What The Issue? Will View an issue.
+
+By default the issue is shown as Github Markdown, styled for reading when
+printing to a terminal (--raw prints it plain), and paged through $PAGER
+if long. --output json or yaml gives the whole issue as Jira returned it,
+and --template formats it with a Go template, like
+--template '{{.Key}} {{.Fields.Status.Name}}'. Templates can use:
+
+  md    converts Jira markup to Github Markdown, like {{md .Fields.Description}}
+  user  shows a user as name and email, like {{user .Fields.Assignee}}
+  date  formats a Jira date, like {{date .Fields.Created}} or {{date .Fields.Created "Jan 2"}}
+
+--fields adds fields like "Story Points,Acceptance Criteria" to the issue,
+with rich text fields in their own section after the description. Without
+it, wti shows the fields listed for the issue's project under "fields" in
+the config file.
+
+Images attached to the issue are shown from their attachment's URL.
+--download-images saves them into a directory instead, and links them
+from there by a path relative to the current directory, so save the
+Markdown in the current directory too.
+
+Issue keys like TEAM-1234 are linked to the issues. Keys in the issue's
+own project are always linked, and others only once Jira confirms they
+are issues, so things like UTF-8 stay as they are. Each of those other
+keys costs a request to Jira, unless "issue_key_pattern" is set in the
+config file: then every key it matches is linked without asking.
+--link-summaries adds each linked issue's summary after the link, or
+--link-summaries=tooltip as its tooltip.
+
+--tree instead shows the issue's parent, epic, subtasks and linked issues as a
+tree, following links --depth levels deep.`,
+	Args: cobra.RangeArgs(0, 1),
 	Run: func(cmd *cobra.Command, args []string) {
 		if jiraConfig == nil {
 			configure()
 		}
+		if lastComments < 0 {
+			fmt.Println("--last must not be negative")
+			os.Exit(exitFail)
+		}
+		if lastComments > 0 {
+			// asking for the last few comments means showing comments
+			showComments = true
+		}
 		var issueKey string
 		if len(args) == 0 {
 			issueKey = getIssueFromGitBranch()
@@ -27,24 +100,431 @@ var wtiCmd = &cobra.Command{
 			issueKey = args[0]
 		}
 
-		jiraIssue, issueErr := atlassian.GetIssue(jiraClient, issueKey)
+		if showTree {
+			tree, err := atlassian.GetIssueTree(jiraClient, issueKey, treeDepth)
+			if err != nil {
+				fmt.Printf("Unable to get Issue %s: %+v\n", issueKey, err)
+				os.Exit(exitFail)
+			}
+			printTree(tree)
+			return
+		}
+
+		var jiraIssue *jira.Issue
+		var issueErr error
+		if jiraConfig.UsesADF() {
+			jiraIssue, issueDocs, issueErr = atlassian.GetIssueV3(jiraClient, issueKey, nil)
+		} else {
+			jiraIssue, issueErr = atlassian.GetIssue(jiraClient, issueKey)
+		}
 		if issueErr != nil {
 			fmt.Println(issueErr)
 		}
 
 		if issueErr == nil && jiraIssue != nil {
-			if !omitTitle {
-				fmt.Printf("%s - %s\n\n", jiraIssue.Key, jiraIssue.Fields.Summary)
+			shownFields = resolveShownFields(issueKey)
+			issueImages = imageResolver(jiraIssue)
+			issueLinks = issueLinker(jiraIssue)
+			if showHistory {
+				history, err := atlassian.GetIssueHistory(jiraClient, issueKey)
+				if err != nil {
+					fmt.Println(err)
+					os.Exit(exitFail)
+				}
+				jiraIssue.Changelog = history.Changelog
 			}
-			if !omitDescription {
-				fmt.Println(
-					atlassian.JiraMarkupToGithubMarkdown(
-						jiraClient, jiraIssue.Fields.Description))
+			if err := printIssue(jiraIssue); err != nil {
+				fmt.Println(err)
+				os.Exit(exitFail)
 			}
 		}
 	},
 }
 
+// printIssue shows the issue in whichever format was asked for
+func printIssue(jiraIssue *jira.Issue) error {
+	if wtiTemplate != "" {
+		tmpl, err := template.New("wti").Funcs(templateFuncs()).Parse(wtiTemplate)
+		if err != nil {
+			return fmt.Errorf("unable to parse template: %w", err)
+		}
+		if err := tmpl.Execute(os.Stdout, jiraIssue); err != nil {
+			return fmt.Errorf("unable to execute template: %w", err)
+		}
+		fmt.Println()
+		return nil
+	}
+
+	switch wtiOutput {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(jiraIssue)
+	case "yaml":
+		// go through JSON so the yaml keys match Jira's field names
+		raw, err := json.Marshal(jiraIssue)
+		if err != nil {
+			return err
+		}
+		var generic interface{}
+		if err := json.Unmarshal(raw, &generic); err != nil {
+			return err
+		}
+		out, err := yaml.Marshal(generic)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(out)
+		return err
+	case "", "markdown", "md":
+		if rawOutput || !isatty.IsTerminal(os.Stdout.Fd()) {
+			fmt.Print(issueMarkdown(jiraIssue, !omitTitle))
+			return nil
+		}
+		page(styledIssue(jiraIssue))
+		return nil
+	default:
+		return fmt.Errorf("unknown output format %q, expected markdown, json or yaml", wtiOutput)
+	}
+}
+
+// issueMarkdown renders the issue as Github Markdown
+func issueMarkdown(jiraIssue *jira.Issue, withTitle bool) string {
+	var b strings.Builder
+	if withTitle {
+		fmt.Fprintf(&b, "%s - %s\n\n", jiraIssue.Key, jiraIssue.Fields.Summary)
+	}
+	list, sections := fieldsMarkdown(jiraIssue)
+	b.WriteString(list)
+	if !omitDescription {
+		b.WriteString(markdownOf(issueDocs.Field("description"), jiraIssue.Fields.Description))
+		b.WriteString("\n")
+	}
+	b.WriteString(sections)
+	if showComments {
+		b.WriteString(commentsMarkdown(jiraIssue))
+	}
+	if showHistory {
+		b.WriteString(historyMarkdown(jiraIssue))
+	}
+	return b.String()
+}
+
+// resolveShownFields finds the fields named by --fields, or failing that
+// the ones configured for the issue's project.
+func resolveShownFields(issueKey string) []jira.Field {
+	names := jiraConfig.Fields.For(atlassian.ProjectKey(issueKey))
+	if fieldList != "" {
+		names = strings.Split(fieldList, ",")
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	all, err := atlassian.GetFields(jiraClient, cfgFile+".fields")
+	if err != nil {
+		fmt.Println(err)
+		return nil
+	}
+	fields, err := atlassian.ResolveFields(all, names)
+	if err != nil {
+		fmt.Println(err)
+	}
+	return fields
+}
+
+// fieldsMarkdown renders the shown fields as a list, along with sections
+// for any rich text fields, which are too long to go in the list.
+func fieldsMarkdown(jiraIssue *jira.Issue) (list, sections string) {
+	if len(shownFields) == 0 {
+		return "", ""
+	}
+	values, err := atlassian.FieldValues(jiraIssue)
+	if err != nil {
+		return "", ""
+	}
+	var l, s strings.Builder
+	for _, f := range shownFields {
+		value := values[f.ID]
+		if atlassian.IsRichText(f) && value != nil {
+			var section string
+			if markup, ok := value.(string); ok {
+				// so images attached to the issue are resolved too
+				section = markdownOf(issueDocs.Field(f.ID), markup)
+			} else {
+				section = atlassian.FormatFieldValue(jiraClient, f, value)
+			}
+			fmt.Fprintf(&s, "## %s\n\n%s\n\n", f.Name, section)
+			continue
+		}
+		fmt.Fprintf(&l, "- **%s:** %s\n", f.Name, atlassian.FormatFieldValue(jiraClient, f, value))
+	}
+	if l.Len() > 0 {
+		l.WriteString("\n")
+	}
+	return l.String(), s.String()
+}
+
+// markdownOf translates rich text from the issue being shown to Github
+// Markdown, from its document if we have one, or else its Jira markup
+func markdownOf(doc *atlassian.Node, markup string) string {
+	opts := atlassian.MarkdownOptions{
+		Image:  issueImages,
+		Issues: issueLinks,
+	}
+	if doc != nil {
+		return atlassian.IssueDocumentToGithubMarkdown(jiraClient, doc, opts)
+	}
+	return atlassian.IssueMarkupToGithubMarkdown(jiraClient, markup, opts)
+}
+
+// issueLinker links issue keys to the Jira site, with the linked issues'
+// summaries if --link-summaries asks for them. Keys in the issue's own
+// project are always linked, others only if they turn out to be issues.
+func issueLinker(jiraIssue *jira.Issue) *atlassian.IssueLinker {
+	linker, err := atlassian.NewIssueLinker(jiraConfig.Host, jiraConfig.IssueKeyPattern)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(exitFail)
+	}
+	linker.Projects = []string{atlassian.ProjectKey(jiraIssue.Key)}
+	linker.Lookup = atlassian.IssueLookup(atlassian.GetQuietJIRAClient(jiraConfig))
+	switch linkSummaries {
+	case "":
+	case "text":
+		linker.Summaries = true
+	case "tooltip":
+		linker.Summaries = true
+		linker.Tooltip = true
+	default:
+		fmt.Printf("unknown --link-summaries %q, expected text or tooltip\n", linkSummaries)
+		os.Exit(exitFail)
+	}
+	return linker
+}
+
+// imageResolver shows images attached to the issue from their attachment's
+// URL, or downloads them into --download-images and shows them from there.
+func imageResolver(jiraIssue *jira.Issue) func(string) string {
+	attachments := jiraIssue.Fields.Attachments
+	if downloadImages == "" {
+		return atlassian.AttachmentImages(attachments)
+	}
+	if err := os.MkdirAll(downloadImages, 0o755); err != nil {
+		fmt.Println(err)
+		os.Exit(exitFail)
+	}
+	httpClient := middleware.NewBasicAuthHTTPClient(jiraConfig.User, jiraConfig.Token)
+	return func(source string) string {
+		a := atlassian.FindAttachment(attachments, source)
+		if a == nil {
+			return source
+		}
+		path := filepath.Join(downloadImages, atlassian.AttachmentFilename(a))
+		if err := atlassian.DownloadAttachment(httpClient, a, path); err != nil {
+			// the Markdown is the output, so complain elsewhere
+			fmt.Fprintln(os.Stderr, err)
+			return a.Content
+		}
+		return imageLink(path)
+	}
+}
+
+// imageLink is how Markdown saved in the current directory refers to path,
+// which is relative even when --download-images isn't.
+func imageLink(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	rel, err := filepath.Rel(wd, abs)
+	if err != nil {
+		// like a path on another drive
+		return filepath.ToSlash(abs)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// commentsMarkdown renders the issue's comment thread, oldest first,
+// as a "## Comments" section.
+func commentsMarkdown(jiraIssue *jira.Issue) string {
+	var comments []*jira.Comment
+	if jiraIssue.Fields.Comments != nil {
+		comments = jiraIssue.Fields.Comments.Comments
+	}
+	if lastComments > 0 && len(comments) > lastComments {
+		comments = comments[len(comments)-lastComments:]
+	}
+
+	var b strings.Builder
+	b.WriteString("\n## Comments\n")
+	if len(comments) == 0 {
+		b.WriteString("\nNo comments.\n")
+	}
+	for _, c := range comments {
+		when, _ := formatDate(c.Created)
+		fmt.Fprintf(&b, "\n### %s - %s\n\n", atlassian.DisplayJiraUser(&c.Author), when)
+		b.WriteString(markdownOf(issueDocs.Comment(c.ID), c.Body))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// styledIssue renders the issue for reading in a terminal: a header panel
+// with the issue's vitals, then its Markdown styled and wrapped to fit.
+func styledIssue(jiraIssue *jira.Issue) string {
+	width, _ := terminalSize()
+	var b strings.Builder
+	if !omitTitle {
+		b.WriteString(issueHeader(jiraIssue, width))
+		b.WriteString("\n")
+	}
+	// the title is already in the header panel
+	b.WriteString(render.Markdown(issueMarkdown(jiraIssue, false), width))
+	b.WriteString("\n")
+	return b.String()
+}
+
+var (
+	headerStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color(colors.ANSIBrightBlack.String())).
+			Padding(0, 1)
+	keyStyle   = lipgloss.NewStyle().Bold(true)
+	labelStyle = blurredStyle.Copy()
+)
+
+// statusColors are the colors Jira uses for each status category
+var statusColors = map[string]lipgloss.AdaptiveColor{
+	"new": {Light: colors.ANSIBlue.String(), Dark: colors.ANSIBrightBlue.String()},
+	"indeterminate": {
+		Light: colors.ANSIYellow.String(),
+		Dark:  colors.ANSIBrightYellow.String(),
+	},
+	"done": {Light: colors.ANSIGreen.String(), Dark: colors.ANSIBrightGreen.String()},
+}
+
+// issueHeader is a panel with the issue's key, summary, status,
+// assignee, priority and type.
+func issueHeader(jiraIssue *jira.Issue, width int) string {
+	fields := jiraIssue.Fields
+	status := "Unknown"
+	statusStyle := lipgloss.NewStyle().Bold(true)
+	if fields.Status != nil {
+		status = fields.Status.Name
+		if c, ok := statusColors[fields.Status.StatusCategory.Key]; ok {
+			statusStyle = statusStyle.Foreground(c)
+		}
+	}
+	priority := "None"
+	if fields.Priority != nil {
+		priority = fields.Priority.Name
+	}
+	assignee := "Unassigned"
+	if fields.Assignee != nil {
+		assignee = fields.Assignee.DisplayName
+	}
+
+	vitals := []string{
+		labelStyle.Render("Status ") + statusStyle.Render(status),
+		labelStyle.Render("Assignee ") + assignee,
+		labelStyle.Render("Priority ") + priority,
+		labelStyle.Render("Type ") + fields.Type.Name,
+	}
+	// leave room for the border and padding
+	inner := width - 4
+	title := keyStyle.Render(jiraIssue.Key) + " " + fields.Summary
+	return headerStyle.Copy().Width(inner).Render(
+		title + "\n" + strings.Join(vitals, labelStyle.Render("  ·  ")))
+}
+
+// printTree shows an issue and its relations as an indented tree
+func printTree(root *atlassian.IssueNode) {
+	fmt.Println(treeLine(root))
+	printBranches(root.Children, "")
+}
+
+func printBranches(nodes []*atlassian.IssueNode, indent string) {
+	for i, node := range nodes {
+		branch, next := "├── ", "│   "
+		if i == len(nodes)-1 {
+			branch, next = "└── ", "    "
+		}
+		fmt.Println(indent + branch + node.Relation + ": " + treeLine(node))
+		printBranches(node.Children, indent+next)
+	}
+}
+
+// treeLine is a node's key, status and summary
+func treeLine(node *atlassian.IssueNode) string {
+	switch {
+	case node.Err != nil:
+		return fmt.Sprintf("%s (unable to get issue: %v)", node.Key, node.Err)
+	case node.Seen:
+		return fmt.Sprintf("%s [%s] %s (see above)", node.Key, node.Status, node.Summary)
+	default:
+		return fmt.Sprintf("%s [%s] %s", node.Key, node.Status, node.Summary)
+	}
+}
+
+// templateFuncs are the helpers available to --template
+func templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"md": func(markup string) string {
+			return markdownOf(nil, markup)
+		},
+		"user": func(u *jira.User) string {
+			if u == nil {
+				return "Unassigned"
+			}
+			return atlassian.DisplayJiraUser(u)
+		},
+		"date": formatDate,
+	}
+}
+
+// formatDate formats the many kinds of Jira dates, with an optional Go time layout
+func formatDate(value interface{}, layout ...string) (string, error) {
+	format := "2006-01-02 15:04"
+	if len(layout) > 0 {
+		format = layout[0]
+	}
+	var t time.Time
+	switch v := value.(type) {
+	case jira.Time:
+		t = time.Time(v)
+	case *jira.Time:
+		if v == nil {
+			return "", nil
+		}
+		t = time.Time(*v)
+	case jira.Date:
+		t = time.Time(v)
+	case *jira.Date:
+		if v == nil {
+			return "", nil
+		}
+		t = time.Time(*v)
+	case time.Time:
+		t = v
+	case string:
+		parsed, err := atlassian.ParseTime(v)
+		if err != nil {
+			return v, nil
+		}
+		t = parsed
+	default:
+		return "", fmt.Errorf("date does not know how to format %T", value)
+	}
+	if t.IsZero() {
+		return "", nil
+	}
+	return t.Local().Format(format), nil
+}
+
 func init() {
 	rootCmd.AddCommand(wtiCmd)
 
@@ -60,4 +540,19 @@ func init() {
 	//.BoolP("toggle", "t", false, "Help message for toggle")
 	flags.BoolVarP(&omitTitle, "no-title", "t", false, "Do Not Print Title")
 	flags.BoolVarP(&omitDescription, "no-description", "d", false, "Do Not Print Description")
+	flags.BoolVarP(&showComments, "comments", "c", false, "Print Comments")
+	flags.IntVar(&lastComments, "last", 0, "Only print the last N comments (implies --comments)")
+	flags.BoolVar(&rawOutput, "raw", false, "Print plain Markdown even in a terminal")
+	flags.StringVarP(&wtiOutput, "output", "o", "markdown", "Output format: markdown, json or yaml")
+	flags.StringVar(&wtiTemplate, "template", "", "Go template to format the issue with")
+	flags.StringVar(&fieldList, "fields", "",
+		`Extra fields to show, by name or ID, like "Story Points,Acceptance Criteria"`)
+	flags.BoolVar(&showHistory, "history", false, "Print who changed the issue and when")
+	flags.BoolVar(&showTree, "tree", false, "Print the parent, epic, subtasks and linked issues as a tree")
+	flags.IntVar(&treeDepth, "depth", 1, "How many levels of linked issues --tree follows")
+	flags.StringVar(&downloadImages, "download-images", "",
+		"Download attached images into this directory and link them relative to the current directory")
+	flags.StringVar(&linkSummaries, "link-summaries", "",
+		"Show linked issues' summaries as text after the link, or as a tooltip")
+	flags.Lookup("link-summaries").NoOptDefVal = "text"
 }
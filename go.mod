@@ -7,11 +7,14 @@ require (
 	github.com/charmbracelet/bubbles v0.8.0
 	github.com/charmbracelet/bubbletea v0.14.1
 	github.com/charmbracelet/lipgloss v0.1.2
+	github.com/containerd/console v1.0.1
 	github.com/magefile/mage v1.11.0
+	github.com/mattn/go-isatty v0.0.13
 	github.com/mitchellh/go-homedir v1.0.0
-	github.com/muesli/reflow v0.3.0 // indirect
+	github.com/muesli/reflow v0.3.0
 	github.com/muesli/termenv v0.9.0 // indirect
 	github.com/spf13/cobra v1.2.1
 	github.com/spf13/viper v1.8.1
+	gopkg.in/yaml.v2 v2.4.0
 	moul.io/http2curl v1.0.0
 )
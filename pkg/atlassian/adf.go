@@ -0,0 +1,721 @@
+package atlassian
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+// ADFNode is a node of an Atlassian Document Format document, which is
+// how version 3 of the Jira Cloud REST API represents rich text.
+type ADFNode struct {
+	Type    string                 `json:"type"`
+	Version int                    `json:"version,omitempty"`
+	Attrs   map[string]interface{} `json:"attrs,omitempty"`
+	Content []*ADFNode             `json:"content,omitempty"`
+	Text    string                 `json:"text,omitempty"`
+	Marks   []ADFMark              `json:"marks,omitempty"`
+}
+
+// ADFMark styles the text it is on, like strong or link
+type ADFMark struct {
+	Type  string                 `json:"type"`
+	Attrs map[string]interface{} `json:"attrs,omitempty"`
+}
+
+// adfPanels maps ADF panel types to the Jira macros closest to them
+var adfPanels = map[string]string{
+	"info":    "info",
+	"note":    "note",
+	"tip":     "tip",
+	"success": "tip",
+	"warning": "warning",
+	"error":   "warning",
+}
+
+// MarkdownToADF translates Github Markdown to an ADF document
+func MarkdownToADF(md string) *ADFNode {
+	return EncodeADF(ParseMarkdown(md))
+}
+
+// DecodeADF translates an ADF document into a document tree
+func DecodeADF(doc *ADFNode) *Node {
+	if doc == nil {
+		return &Node{Kind: DocumentNode}
+	}
+	return &Node{Kind: DocumentNode, Children: adfBlocks(doc.Content)}
+}
+
+func adfBlocks(nodes []*ADFNode) []*Node {
+	var blocks []*Node
+	for _, n := range nodes {
+		if n == nil {
+			// JSON nulls, which Jira doesn't send but others might
+			continue
+		}
+		blocks = append(blocks, adfBlock(n)...)
+	}
+	return blocks
+}
+
+func adfBlock(n *ADFNode) []*Node {
+	switch n.Type {
+	case "paragraph":
+		return []*Node{{Kind: ParagraphNode, Children: adfInlines(n.Content)}}
+	case "heading":
+		level := adfInt(n.Attrs["level"])
+		if level < 1 || level > 6 {
+			level = 1
+		}
+		return []*Node{{Kind: HeadingNode, Level: level, Children: adfInlines(n.Content)}}
+	case "bulletList", "orderedList":
+		list := &Node{Kind: ListNode, Ordered: n.Type == "orderedList"}
+		for _, item := range n.Content {
+			if item == nil {
+				continue
+			}
+			list.Children = append(list.Children,
+				&Node{Kind: ListItemNode, Children: adfBlocks(item.Content)})
+		}
+		return []*Node{list}
+	case "taskList", "decisionList":
+		return []*Node{adfTaskList(n)}
+	case "codeBlock":
+		var text strings.Builder
+		for _, c := range n.Content {
+			if c != nil {
+				text.WriteString(c.Text)
+			}
+		}
+		language, _ := n.Attrs["language"].(string)
+		return []*Node{{Kind: CodeBlockNode, Text: text.String(), Language: language}}
+	case "blockquote":
+		return []*Node{{Kind: QuoteNode, Children: adfBlocks(n.Content)}}
+	case "panel":
+		panelType, _ := n.Attrs["panelType"].(string)
+		macro, ok := adfPanels[panelType]
+		if !ok {
+			macro = "info"
+		}
+		return []*Node{{Kind: PanelNode, Macro: macro, Children: adfBlocks(n.Content)}}
+	case "expand", "nestedExpand":
+		panel := &Node{Kind: PanelNode, Macro: "panel", Children: adfBlocks(n.Content)}
+		if title, _ := n.Attrs["title"].(string); title != "" {
+			panel.Params = map[string]string{"title": title}
+		}
+		return []*Node{panel}
+	case "rule":
+		return []*Node{{Kind: RuleNode}}
+	case "table":
+		return []*Node{adfTable(n)}
+	case "mediaSingle", "mediaGroup":
+		var images []*Node
+		for _, media := range n.Content {
+			if media != nil {
+				images = append(images, adfMedia(media))
+			}
+		}
+		return []*Node{{Kind: ParagraphNode, Children: images}}
+	case "blockCard", "embedCard":
+		link, _ := n.Attrs["url"].(string)
+		return []*Node{{Kind: ParagraphNode, Children: []*Node{{Kind: LinkNode, URL: link}}}}
+	default:
+		// extensions and anything newer than this, shown as best we can
+		if len(n.Content) > 0 {
+			return adfBlocks(n.Content)
+		}
+		return nil
+	}
+}
+
+// adfTaskList translates a task list, whose items hold text directly and
+// whose nested lists sit between the items they belong under.
+func adfTaskList(n *ADFNode) *Node {
+	list := &Node{Kind: ListNode}
+	for _, item := range n.Content {
+		if item == nil {
+			continue
+		}
+		if item.Type == "taskList" || item.Type == "decisionList" {
+			nested := adfTaskList(item)
+			if len(list.Children) == 0 {
+				list.Children = append(list.Children, &Node{Kind: ListItemNode})
+			}
+			last := list.Children[len(list.Children)-1]
+			last.Children = append(last.Children, nested)
+			continue
+		}
+		state, _ := item.Attrs["state"].(string)
+		list.Children = append(list.Children, &Node{
+			Kind:     ListItemNode,
+			Task:     item.Type == "taskItem",
+			Checked:  state == "DONE",
+			Children: []*Node{{Kind: ParagraphNode, Children: adfInlines(item.Content)}},
+		})
+	}
+	return list
+}
+
+func adfTable(n *ADFNode) *Node {
+	table := &Node{Kind: TableNode}
+	for _, row := range n.Content {
+		if row == nil {
+			continue
+		}
+		tableRow := &Node{Kind: TableRowNode}
+		for _, cell := range row.Content {
+			if cell == nil {
+				continue
+			}
+			// cells hold blocks, but ours hold a line of text
+			var inlines []*Node
+			for i, block := range adfBlocks(cell.Content) {
+				if i > 0 {
+					inlines = append(inlines, &Node{Kind: LineBreakNode})
+				}
+				if block.Kind == ParagraphNode || block.Kind == HeadingNode {
+					inlines = append(inlines, block.Children...)
+				} else {
+					inlines = append(inlines, &Node{Kind: TextNode, Text: RenderMarkdown(
+						&Node{Kind: DocumentNode, Children: []*Node{block}}, MarkdownOptions{})})
+				}
+			}
+			tableRow.Children = append(tableRow.Children, &Node{
+				Kind:     TableCellNode,
+				Header:   cell.Type == "tableHeader",
+				Children: inlines,
+			})
+		}
+		table.Children = append(table.Children, tableRow)
+	}
+	return table
+}
+
+// adfMedia translates an image. Attachments are named by their file
+// name, like they are in wiki markup.
+func adfMedia(n *ADFNode) *Node {
+	image := &Node{Kind: ImageNode, Params: map[string]string{}}
+	alt, _ := n.Attrs["alt"].(string)
+	if link, _ := n.Attrs["url"].(string); link != "" {
+		image.URL = link
+		if alt != "" {
+			image.Params["alt"] = alt
+		}
+	} else if alt != "" {
+		image.URL = alt
+	} else {
+		image.URL, _ = n.Attrs["id"].(string)
+	}
+	if width := adfInt(n.Attrs["width"]); width > 0 {
+		image.Params["width"] = strconv.Itoa(width)
+	}
+	return image
+}
+
+func adfInlines(nodes []*ADFNode) []*Node {
+	var inlines []*Node
+	for _, n := range nodes {
+		if n == nil {
+			continue
+		}
+		var node *Node
+		switch n.Type {
+		case "text":
+			node = &Node{Kind: TextNode, Text: n.Text}
+		case "hardBreak":
+			node = &Node{Kind: LineBreakNode}
+		case "mention":
+			id, _ := n.Attrs["id"].(string)
+			node = &Node{Kind: MentionNode, Text: id}
+		case "emoji":
+			text, _ := n.Attrs["text"].(string)
+			if text == "" {
+				text, _ = n.Attrs["shortName"].(string)
+			}
+			node = &Node{Kind: TextNode, Text: text}
+		case "inlineCard":
+			link, _ := n.Attrs["url"].(string)
+			node = &Node{Kind: LinkNode, URL: link}
+		case "status":
+			// status lozenges are shown in capitals, like Jira does
+			text, _ := n.Attrs["text"].(string)
+			node = &Node{Kind: MonospaceNode, Text: strings.ToUpper(text)}
+		case "date":
+			node = &Node{Kind: TextNode, Text: adfDate(n.Attrs["timestamp"])}
+		case "media", "mediaInline":
+			node = adfMedia(n)
+		default:
+			if n.Text == "" {
+				continue
+			}
+			node = &Node{Kind: TextNode, Text: n.Text}
+		}
+		inlines = appendInline(inlines, adfApplyMarks(node, n.Marks))
+	}
+	return inlines
+}
+
+// appendInline adds node to nodes, merging it into the last node when both
+// have the same style. ADF marks each piece of text separately, so bold text
+// with an italic word in it comes as three bold pieces.
+func appendInline(nodes []*Node, node *Node) []*Node {
+	last := len(nodes) - 1
+	if last < 0 || !sameStyle(nodes[last], node) {
+		return append(nodes, node)
+	}
+	for _, child := range node.Children {
+		nodes[last].Children = appendInline(nodes[last].Children, child)
+	}
+	return nodes
+}
+
+func sameStyle(a, b *Node) bool {
+	if a.Kind != b.Kind || len(a.Children) == 0 || len(b.Children) == 0 {
+		return false
+	}
+	switch a.Kind {
+	case StrongNode, EmphasisNode, StrikeNode, InsertNode, SuperscriptNode, SubscriptNode:
+		return true
+	case LinkNode:
+		return a.URL == b.URL
+	}
+	return false
+}
+
+// adfMarkOrder lists marks from the innermost out. Wrapping them in a
+// fixed order, whatever order they came in, lets neighbouring text with
+// the same marks be merged.
+var adfMarkOrder = []string{"code", "subsup", "underline", "strike", "em", "strong", "link"}
+
+// adfApplyMarks wraps a node in its marks, with any link outermost
+func adfApplyMarks(node *Node, marks []ADFMark) *Node {
+	for _, markType := range adfMarkOrder {
+		for _, mark := range marks {
+			if mark.Type != markType {
+				continue
+			}
+			switch mark.Type {
+			case "code":
+				if node.Kind == TextNode {
+					node = &Node{Kind: MonospaceNode, Text: node.Text}
+				}
+			case "subsup":
+				kind := SuperscriptNode
+				if t, _ := mark.Attrs["type"].(string); t == "sub" {
+					kind = SubscriptNode
+				}
+				node = &Node{Kind: kind, Children: []*Node{node}}
+			case "underline":
+				node = &Node{Kind: InsertNode, Children: []*Node{node}}
+			case "strike":
+				node = &Node{Kind: StrikeNode, Children: []*Node{node}}
+			case "em":
+				node = &Node{Kind: EmphasisNode, Children: []*Node{node}}
+			case "strong":
+				node = &Node{Kind: StrongNode, Children: []*Node{node}}
+			case "link":
+				href, _ := mark.Attrs["href"].(string)
+				node = &Node{Kind: LinkNode, URL: href, Children: []*Node{node}}
+			}
+		}
+	}
+	return node
+}
+
+func adfInt(v interface{}) int {
+	switch n := v.(type) {
+	case float64:
+		return int(n)
+	case int:
+		return n
+	case string:
+		i, _ := strconv.Atoi(n)
+		return i
+	}
+	return 0
+}
+
+// adfDate formats a date node's timestamp, which is in milliseconds
+func adfDate(v interface{}) string {
+	var ms int64
+	switch t := v.(type) {
+	case string:
+		ms, _ = strconv.ParseInt(t, 10, 64)
+	case float64:
+		ms = int64(t)
+	}
+	return time.Unix(ms/1000, 0).UTC().Format("2006-01-02")
+}
+
+// EncodeADF translates a document tree into an ADF document
+func EncodeADF(doc *Node) *ADFNode {
+	e := &adfEncoder{}
+	return &ADFNode{Type: "doc", Version: 1, Content: e.blocks(doc.Children)}
+}
+
+type adfEncoder struct {
+	// tasks counts task items, which each need an ID
+	tasks int
+}
+
+func (e *adfEncoder) blocks(nodes []*Node) []*ADFNode {
+	var blocks []*ADFNode
+	for _, n := range nodes {
+		blocks = append(blocks, e.block(n)...)
+	}
+	return blocks
+}
+
+func (e *adfEncoder) block(n *Node) []*ADFNode {
+	switch n.Kind {
+	case ParagraphNode:
+		return e.paragraph(n.Children)
+	case HeadingNode:
+		return []*ADFNode{{
+			Type:    "heading",
+			Attrs:   map[string]interface{}{"level": n.Level},
+			Content: e.inlines(n.Children, nil),
+		}}
+	case ListNode:
+		return []*ADFNode{e.list(n)}
+	case CodeBlockNode:
+		code := &ADFNode{Type: "codeBlock"}
+		if n.Language != "" {
+			code.Attrs = map[string]interface{}{"language": n.Language}
+		}
+		if n.Text != "" {
+			code.Content = []*ADFNode{{Type: "text", Text: n.Text}}
+		}
+		return []*ADFNode{code}
+	case QuoteNode:
+		return []*ADFNode{{Type: "blockquote", Content: e.blocks(n.Children)}}
+	case PanelNode:
+		panelType := n.Macro
+		if _, ok := adfPanels[panelType]; !ok {
+			panelType = "info"
+		}
+		content := e.blocks(n.Children)
+		if title := n.Params["title"]; title != "" {
+			heading := &ADFNode{Type: "paragraph", Content: []*ADFNode{{
+				Type:  "text",
+				Text:  title,
+				Marks: []ADFMark{{Type: "strong"}},
+			}}}
+			content = append([]*ADFNode{heading}, content...)
+		}
+		return []*ADFNode{{
+			Type:    "panel",
+			Attrs:   map[string]interface{}{"panelType": panelType},
+			Content: content,
+		}}
+	case TableNode:
+		return []*ADFNode{e.table(n)}
+	case RuleNode:
+		return []*ADFNode{{Type: "rule"}}
+	default:
+		return e.paragraph([]*Node{n})
+	}
+}
+
+// paragraph encodes a paragraph, lifting any images out into blocks of
+// their own since ADF only has block images.
+func (e *adfEncoder) paragraph(inlines []*Node) []*ADFNode {
+	var blocks []*ADFNode
+	var run []*Node
+	flush := func() {
+		if content := e.inlines(run, nil); len(content) > 0 {
+			blocks = append(blocks, &ADFNode{Type: "paragraph", Content: content})
+		}
+		run = nil
+	}
+	for _, n := range inlines {
+		if n.Kind != ImageNode {
+			if len(run) == 0 && len(blocks) > 0 && n.Kind == TextNode {
+				// text after an image starts a paragraph of its own
+				n = &Node{Kind: TextNode, Text: strings.TrimLeft(n.Text, " ")}
+			}
+			run = append(run, n)
+			continue
+		}
+		flush()
+		media := &ADFNode{Type: "media", Attrs: map[string]interface{}{"type": "external", "url": n.URL}}
+		if alt := n.Params["alt"]; alt != "" {
+			media.Attrs["alt"] = alt
+		}
+		blocks = append(blocks, &ADFNode{
+			Type:    "mediaSingle",
+			Attrs:   map[string]interface{}{"layout": "center"},
+			Content: []*ADFNode{media},
+		})
+	}
+	flush()
+	if len(blocks) == 0 {
+		blocks = append(blocks, &ADFNode{Type: "paragraph"})
+	}
+	return blocks
+}
+
+func (e *adfEncoder) list(n *Node) *ADFNode {
+	tasks := len(n.Children) > 0
+	for _, item := range n.Children {
+		tasks = tasks && item.Task
+	}
+	if tasks {
+		list := &ADFNode{Type: "taskList", Attrs: map[string]interface{}{"localId": e.localID()}}
+		for _, item := range n.Children {
+			state := "TODO"
+			if item.Checked {
+				state = "DONE"
+			}
+			task := &ADFNode{
+				Type:  "taskItem",
+				Attrs: map[string]interface{}{"localId": e.localID(), "state": state},
+			}
+			list.Content = append(list.Content, task)
+			for _, child := range item.Children {
+				if child.Kind == ListNode {
+					// nested lists follow the item they belong to
+					list.Content = append(list.Content, e.list(child))
+					continue
+				}
+				task.Content = append(task.Content, e.inlines(child.Children, nil)...)
+			}
+		}
+		return list
+	}
+
+	list := &ADFNode{Type: "bulletList"}
+	if n.Ordered {
+		list.Type = "orderedList"
+	}
+	for _, item := range n.Children {
+		content := e.blocks(item.Children)
+		if len(content) == 0 || content[0].Type != "paragraph" {
+			// list items must start with a paragraph
+			content = append([]*ADFNode{{Type: "paragraph"}}, content...)
+		}
+		if item.Task {
+			// a task in a list of other things keeps its box as text
+			box := &ADFNode{Type: "text", Text: "[ ] "}
+			if item.Checked {
+				box.Text = "[x] "
+			}
+			content[0].Content = append([]*ADFNode{box}, content[0].Content...)
+		}
+		list.Content = append(list.Content, &ADFNode{Type: "listItem", Content: content})
+	}
+	return list
+}
+
+func (e *adfEncoder) localID() string {
+	e.tasks++
+	return strconv.Itoa(e.tasks)
+}
+
+func (e *adfEncoder) table(n *Node) *ADFNode {
+	table := &ADFNode{Type: "table"}
+	for _, row := range n.Children {
+		tableRow := &ADFNode{Type: "tableRow"}
+		for _, cell := range row.Children {
+			cellType := "tableCell"
+			if cell.Header {
+				cellType = "tableHeader"
+			}
+			paragraph := &ADFNode{Type: "paragraph", Content: e.inlines(cell.Children, nil)}
+			tableRow.Content = append(tableRow.Content,
+				&ADFNode{Type: cellType, Content: []*ADFNode{paragraph}})
+		}
+		table.Content = append(table.Content, tableRow)
+	}
+	return table
+}
+
+// inlines encodes inline nodes as ADF text, carrying marks down to the
+// text they style.
+func (e *adfEncoder) inlines(nodes []*Node, marks []ADFMark) []*ADFNode {
+	var content []*ADFNode
+	with := func(mark ADFMark) []ADFMark {
+		return append(append([]ADFMark{}, marks...), mark)
+	}
+	for _, n := range nodes {
+		switch n.Kind {
+		case TextNode:
+			if n.Text != "" {
+				content = append(content, &ADFNode{Type: "text", Text: n.Text, Marks: marks})
+			}
+		case StrongNode:
+			content = append(content, e.inlines(n.Children, with(ADFMark{Type: "strong"}))...)
+		case EmphasisNode, CitationNode:
+			content = append(content, e.inlines(n.Children, with(ADFMark{Type: "em"}))...)
+		case StrikeNode:
+			content = append(content, e.inlines(n.Children, with(ADFMark{Type: "strike"}))...)
+		case InsertNode:
+			content = append(content, e.inlines(n.Children, with(ADFMark{Type: "underline"}))...)
+		case SuperscriptNode, SubscriptNode:
+			kind := "sup"
+			if n.Kind == SubscriptNode {
+				kind = "sub"
+			}
+			mark := ADFMark{Type: "subsup", Attrs: map[string]interface{}{"type": kind}}
+			content = append(content, e.inlines(n.Children, with(mark))...)
+		case MonospaceNode:
+			// code can only be combined with links
+			codeMarks := []ADFMark{{Type: "code"}}
+			for _, m := range marks {
+				if m.Type == "link" {
+					codeMarks = append(codeMarks, m)
+				}
+			}
+			if n.Text != "" {
+				content = append(content, &ADFNode{Type: "text", Text: n.Text, Marks: codeMarks})
+			}
+		case LinkNode:
+			mark := ADFMark{Type: "link", Attrs: map[string]interface{}{"href": n.URL}}
+			children := n.Children
+			if len(children) == 0 {
+				children = []*Node{{Kind: TextNode, Text: n.URL}}
+			}
+			content = append(content, e.inlines(children, with(mark))...)
+		case ImageNode:
+			// images inside headings and cells can only be links
+			mark := ADFMark{Type: "link", Attrs: map[string]interface{}{"href": n.URL}}
+			content = append(content, &ADFNode{Type: "text", Text: n.URL, Marks: with(mark)})
+		case MentionNode:
+			content = append(content, &ADFNode{
+				Type:  "mention",
+				Attrs: map[string]interface{}{"id": n.Text},
+			})
+		case LineBreakNode:
+			content = append(content, &ADFNode{Type: "hardBreak"})
+		default:
+			content = append(content, e.inlines(n.Children, marks)...)
+		}
+	}
+	return content
+}
+
+// IssueDocuments are the rich text of an issue fetched through version 3
+// of the REST API, kept as documents so they can be rendered with
+// everything ADF can say that wiki markup can't, like status lozenges.
+type IssueDocuments struct {
+	// Fields are rich text fields by field ID, like "description"
+	Fields map[string]*Node
+	// Comments are comment bodies by comment ID
+	Comments map[string]*Node
+}
+
+// Field returns a rich text field's document, or nil if there isn't one
+func (d *IssueDocuments) Field(id string) *Node {
+	if d == nil {
+		return nil
+	}
+	return d.Fields[id]
+}
+
+// Comment returns a comment's document, or nil if there isn't one
+func (d *IssueDocuments) Comment(id string) *Node {
+	if d == nil {
+		return nil
+	}
+	return d.Comments[id]
+}
+
+// GetIssueV3 fetches an issue through version 3 of the REST API, which
+// returns rich text as ADF. The rich text is returned as documents, and
+// the issue's own fields hold it as wiki markup, so the issue can still be
+// used just like one from version 2 for JSON, templates and diffs.
+func GetIssueV3(
+	jiraClient *jira.Client,
+	issueKey string,
+	options *jira.GetQueryOptions,
+) (*jira.Issue, *IssueDocuments, error) {
+	endpoint := fmt.Sprintf("rest/api/3/issue/%s", issueKey)
+	if options != nil {
+		query := url.Values{}
+		if options.Expand != "" {
+			query.Set("expand", options.Expand)
+		}
+		if options.Fields != "" {
+			query.Set("fields", options.Fields)
+		}
+		if len(query) > 0 {
+			endpoint += "?" + query.Encode()
+		}
+	}
+	var raw map[string]interface{}
+	if err := getJSON(jiraClient, endpoint, &raw); err != nil {
+		return nil, nil, err
+	}
+	docs := &IssueDocuments{Fields: make(map[string]*Node), Comments: make(map[string]*Node)}
+	if fields, ok := raw["fields"].(map[string]interface{}); ok {
+		for id, value := range fields {
+			if doc := adfDocument(value); doc != nil {
+				docs.Fields[id] = doc
+				fields[id] = RenderJira(doc)
+			}
+		}
+		if comments, ok := fields["comment"].(map[string]interface{}); ok {
+			list, _ := comments["comments"].([]interface{})
+			for _, c := range list {
+				comment, _ := c.(map[string]interface{})
+				id, _ := comment["id"].(string)
+				if doc := adfDocument(comment["body"]); doc != nil {
+					docs.Comments[id] = doc
+					comment["body"] = RenderJira(doc)
+				}
+			}
+		}
+		// anything else, like worklog comments, only needs to fit the issue
+		adfToWiki(fields)
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+	issue := new(jira.Issue)
+	if err := json.Unmarshal(data, issue); err != nil {
+		return nil, nil, err
+	}
+	return issue, docs, nil
+}
+
+// adfDocument decodes v if it is an ADF document from decoded JSON
+func adfDocument(v interface{}) *Node {
+	x, ok := v.(map[string]interface{})
+	if !ok || x["type"] != "doc" {
+		return nil
+	}
+	data, err := json.Marshal(x)
+	if err != nil {
+		return nil
+	}
+	var doc ADFNode
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil
+	}
+	return DecodeADF(&doc)
+}
+
+// adfToWiki replaces every ADF document in a decoded JSON value with wiki
+// markup, so it fits the string fields of jira.Issue
+func adfToWiki(v interface{}) interface{} {
+	if doc := adfDocument(v); doc != nil {
+		return RenderJira(doc)
+	}
+	switch x := v.(type) {
+	case map[string]interface{}:
+		for key, value := range x {
+			x[key] = adfToWiki(value)
+		}
+	case []interface{}:
+		for i, value := range x {
+			x[i] = adfToWiki(value)
+		}
+	}
+	return v
+}
@@ -0,0 +1,159 @@
+package atlassian
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+func TestDecodeADF(t *testing.T) {
+	tests := []struct {
+		name string
+		adf  string
+		want string
+	}{
+		{
+			name: "marks merging",
+			adf: `{"type": "doc", "content": [{"type": "paragraph", "content": [
+				{"type": "text", "text": "bold ", "marks": [{"type": "strong"}]},
+				{"type": "text", "text": "nested", "marks": [{"type": "em"}, {"type": "strong"}]}]}]}`,
+			want: "**bold *nested***",
+		},
+		{
+			name: "nested task list",
+			adf: `{"type": "doc", "content": [{"type": "taskList", "content": [
+				{"type": "taskItem", "attrs": {"state": "DONE"}, "content": [{"type": "text", "text": "one"}]},
+				{"type": "taskList", "content": [
+					{"type": "taskItem", "attrs": {"state": "TODO"}, "content": [{"type": "text", "text": "two"}]}]}]}]}`,
+			want: "* [x] one\n  * [ ] two",
+		},
+		{
+			name: "panel",
+			adf: `{"type": "doc", "content": [{"type": "panel", "attrs": {"panelType": "warning"}, "content": [
+				{"type": "paragraph", "content": [{"type": "text", "text": "careful"}]}]}]}`,
+			want: "> [!CAUTION]\n> careful",
+		},
+		{
+			name: "status and mention",
+			adf: `{"type": "doc", "content": [{"type": "paragraph", "content": [
+				{"type": "status", "attrs": {"text": "In progress", "color": "blue"}},
+				{"type": "text", "text": " for "},
+				{"type": "mention", "attrs": {"id": "557058:abc", "text": "@Ada"}}]}]}`,
+			want: "`IN PROGRESS` for @ada",
+		},
+		{
+			name: "table cell with several blocks",
+			adf: `{"type": "doc", "content": [{"type": "table", "content": [
+				{"type": "tableRow", "content": [{"type": "tableHeader", "content": [
+					{"type": "paragraph", "content": [{"type": "text", "text": "h"}]}]}]},
+				{"type": "tableRow", "content": [{"type": "tableCell", "content": [
+					{"type": "paragraph", "content": [{"type": "text", "text": "a"}]},
+					{"type": "paragraph", "content": [{"type": "text", "text": "c"}]}]}]}]}]}`,
+			want: "| h |\n| --- |\n| a<br>c |",
+		},
+		{
+			name: "null nodes",
+			adf: `{"type": "doc", "content": [null,
+				{"type": "paragraph", "content": [null, {"type": "text", "text": "x"}]},
+				{"type": "bulletList", "content": [null, {"type": "listItem", "content": [
+					{"type": "paragraph", "content": [{"type": "text", "text": "y"}]}]}]},
+				{"type": "table", "content": [null, {"type": "tableRow", "content": [null, {"type": "tableHeader", "content": [
+					{"type": "paragraph", "content": [{"type": "text", "text": "z"}]}]}]}]},
+				{"type": "codeBlock", "content": [null, {"type": "text", "text": "code"}]}]}`,
+			want: "x\n\n* y\n\n| z |\n| --- |\n\n```\ncode\n```",
+		},
+	}
+	mention := func(id string) string {
+		if id == "557058:abc" {
+			return "@ada"
+		}
+		return id
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc ADFNode
+			if err := json.Unmarshal([]byte(tt.adf), &doc); err != nil {
+				t.Fatal(err)
+			}
+			if got := RenderMarkdown(DecodeADF(&doc), MarkdownOptions{Mention: mention}); got != tt.want {
+				t.Errorf("RenderMarkdown(DecodeADF()) = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkdownToADF(t *testing.T) {
+	got := MarkdownToADF("**bold *nested***\n\n- [x] one\n  - [ ] two")
+	want := &ADFNode{Type: "doc", Version: 1, Content: []*ADFNode{
+		{Type: "paragraph", Content: []*ADFNode{
+			{Type: "text", Text: "bold ", Marks: []ADFMark{{Type: "strong"}}},
+			{Type: "text", Text: "nested", Marks: []ADFMark{{Type: "strong"}, {Type: "em"}}},
+		}},
+		{Type: "taskList", Attrs: map[string]interface{}{"localId": "1"}, Content: []*ADFNode{
+			{Type: "taskItem", Attrs: map[string]interface{}{"localId": "2", "state": "DONE"},
+				Content: []*ADFNode{{Type: "text", Text: "one"}}},
+			{Type: "taskList", Attrs: map[string]interface{}{"localId": "3"}, Content: []*ADFNode{
+				{Type: "taskItem", Attrs: map[string]interface{}{"localId": "4", "state": "TODO"},
+					Content: []*ADFNode{{Type: "text", Text: "two"}}},
+			}},
+		}},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		gotJSON, _ := json.Marshal(got)
+		wantJSON, _ := json.Marshal(want)
+		t.Errorf("MarkdownToADF() = %s, want %s", gotJSON, wantJSON)
+	}
+}
+
+func TestADFRoundTrip(t *testing.T) {
+	md := "# Title\n\nSome **bold *nested*** and `code`.\n\n" +
+		"* [x] done\n  * [ ] todo\n\n1. one\n1. two\n\n" +
+		"| a | b |\n| --- | --- |\n| 1 | 2 |\n\n" +
+		"> [!NOTE]\n> careful\n\n```go\nfmt.Println()\n```"
+	if got := RenderMarkdown(DecodeADF(MarkdownToADF(md)), MarkdownOptions{}); got != md {
+		t.Errorf("Markdown -> ADF -> Markdown = %q, want %q", got, md)
+	}
+}
+
+func TestGetIssueV3(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"key": "TEAM-1", "fields": {
+			"summary": "Reliable uploads",
+			"description": {"type": "doc", "version": 1, "content": [{"type": "paragraph", "content": [
+				{"type": "text", "text": "Now "},
+				{"type": "status", "attrs": {"text": "In progress"}}]}]},
+			"comment": {"comments": [{"id": "100", "body": {"type": "doc", "version": 1, "content": [
+				{"type": "panel", "attrs": {"panelType": "success"}, "content": [
+					{"type": "paragraph", "content": [
+						{"type": "mention", "attrs": {"id": "557058:abc"}},
+						{"type": "text", "text": " shipped it"}]}]}]}}]}}}`))
+	}))
+	defer srv.Close()
+	jiraClient, err := jira.NewClient(nil, srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issue, docs, err := GetIssueV3(jiraClient, "TEAM-1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := MarkdownOptions{Mention: func(id string) string { return "@" + id }}
+	if got, want := RenderMarkdown(docs.Field("description"), opts), "Now `IN PROGRESS`"; got != want {
+		t.Errorf("description = %q, want %q", got, want)
+	}
+	if got, want := RenderMarkdown(docs.Comment("100"), opts), "> [!TIP]\n> @557058:abc shipped it"; got != want {
+		t.Errorf("comment = %q, want %q", got, want)
+	}
+	// the issue itself gets wiki markup, like version 2 gives
+	if got, want := issue.Fields.Description, "Now {{IN PROGRESS}}"; got != want {
+		t.Errorf("Fields.Description = %q, want %q", got, want)
+	}
+	if got := issue.Fields.Comments.Comments[0].Body; !strings.Contains(got, "[~accountid:557058:abc] shipped it") {
+		t.Errorf("comment body = %q, want the mention as wiki markup", got)
+	}
+}
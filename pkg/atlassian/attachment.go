@@ -0,0 +1,160 @@
+package atlassian
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+// AttachmentFilename is a safe local name for an attachment, without
+// any directories the uploader may have put in it.
+func AttachmentFilename(attachment *jira.Attachment) string {
+	name := filepath.Base(filepath.FromSlash(attachment.Filename))
+	if name == "." || name == ".." || name == string(filepath.Separator) {
+		return attachment.ID
+	}
+	return name
+}
+
+// FindAttachment returns the attachment an image or link in an issue's
+// markup names, like !screenshot.png!, or nil if there isn't one. When a
+// name has been attached more than once the newest is used, as Jira does.
+func FindAttachment(attachments []*jira.Attachment, name string) *jira.Attachment {
+	name = strings.TrimPrefix(strings.TrimSpace(name), "^")
+	if name == "" || strings.Contains(name, "://") {
+		return nil
+	}
+	var found *jira.Attachment
+	var foundAt time.Time
+	for _, a := range attachments {
+		if a == nil || !strings.EqualFold(a.Filename, name) {
+			continue
+		}
+		created, _ := ParseTime(a.Created)
+		if found == nil || created.After(foundAt) {
+			found, foundAt = a, created
+		}
+	}
+	return found
+}
+
+// AttachmentImages resolves image sources that name one of attachments to
+// the attachment's content URL, for MarkdownOptions.Image. Other sources
+// are left as they are.
+func AttachmentImages(attachments []*jira.Attachment) func(string) string {
+	return func(source string) string {
+		if a := FindAttachment(attachments, source); a != nil && a.Content != "" {
+			return a.Content
+		}
+		return source
+	}
+}
+
+// DownloadAttachment saves an attachment to path using httpClient, which
+// must be authenticated. It downloads to path.part first, so an interrupted
+// download carries on where it left off, and only moves it into place once
+// it is the size Jira says it should be.
+func DownloadAttachment(httpClient *http.Client, attachment *jira.Attachment, path string) error {
+	size := int64(attachment.Size)
+	if info, err := os.Stat(path); err == nil && info.Size() == size {
+		// already downloaded
+		return nil
+	}
+
+	partial := path + ".part"
+	f, err := os.OpenFile(partial, os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	offset, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if offset > size {
+		// not the file we were downloading before
+		if err := restart(f); err != nil {
+			return err
+		}
+		offset = 0
+	}
+
+	if offset < size {
+		req, err := http.NewRequest(http.MethodGet, attachment.Content, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Accept", "*/*")
+		if offset > 0 {
+			req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		}
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("unable to download %s: %w", attachment.Filename, err)
+		}
+		defer resp.Body.Close()
+
+		switch resp.StatusCode {
+		case http.StatusPartialContent:
+			// carrying on from offset
+		case http.StatusOK:
+			// the server sent the whole thing
+			if err := restart(f); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unable to download %s: %s", attachment.Filename, resp.Status)
+		}
+		if _, err := io.Copy(f, resp.Body); err != nil {
+			return fmt.Errorf("download of %s interrupted, run again to resume: %w",
+				attachment.Filename, err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	info, err := os.Stat(partial)
+	if err != nil {
+		return err
+	}
+	if info.Size() != size {
+		return fmt.Errorf("downloaded %d bytes of %s, but expected %d",
+			info.Size(), attachment.Filename, size)
+	}
+	return os.Rename(partial, path)
+}
+
+// restart empties a partial download
+func restart(f *os.File) error {
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	_, err := f.Seek(0, io.SeekStart)
+	return err
+}
+
+// UploadAttachment attaches the file at path to an issue, returning the
+// attachments Jira created. A dry run creates none.
+func UploadAttachment(jiraClient *jira.Client, issueKey, path string) ([]jira.Attachment, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	attachments, _, err := jiraClient.Issue.PostAttachment(issueKey, f, filepath.Base(path))
+	if err != nil {
+		return nil, err
+	}
+	if attachments == nil {
+		return nil, nil
+	}
+	return *attachments, nil
+}
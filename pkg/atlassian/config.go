@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/user"
 	"path/filepath"
+	"regexp"
 	"strings"
 )
 
@@ -15,6 +16,74 @@ type Config struct {
 	Host  string `json:"host"  mapstructure:"host"`
 	User  string `json:"user"  mapstructure:"user"`
 	Token string `json:"token" mapstructure:"token"`
+	// Aliases are short names for statuses, by project key
+	Aliases StatusAliases `json:"aliases,omitempty" mapstructure:"aliases"`
+	// Hooks are local commands to run around transitions
+	Hooks Hooks `json:"hooks,omitempty" mapstructure:"hooks"`
+	// Fields are the fields wti shows, by project key
+	Fields FieldNames `json:"fields,omitempty" mapstructure:"fields"`
+	// APIVersion is the REST API version to read issues with, "2" or "3".
+	// Version 3 returns rich text as ADF instead of wiki markup.
+	APIVersion string `json:"api_version,omitempty" mapstructure:"api_version"`
+	// IssueKeyPattern is a regular expression for the issue keys to link
+	// in converted Markdown. Empty means IssueKeyPattern.
+	IssueKeyPattern string `json:"issue_key_pattern,omitempty" mapstructure:"issue_key_pattern"`
+	// DryRun means nothing should be changed in Jira, only described.
+	// It comes from the command line, so it is never saved.
+	DryRun bool `json:"-" mapstructure:"-"`
+}
+
+// UsesADF reports whether issues should be read with version 3 of the REST API
+func (c *Config) UsesADF() bool {
+	return strings.TrimSpace(c.APIVersion) == "3"
+}
+
+// StatusAliases maps project keys to alias tables, which map short words
+// like "wip" to the real status names in that project. The "*" project
+// holds fallbacks for every project.
+type StatusAliases map[string]map[string]string
+
+// anyProject is the key for aliases that apply to every project
+const anyProject = "*"
+
+// For returns the effective alias table for a project, keyed by lower case alias.
+func (a StatusAliases) For(projectKey string) map[string]string {
+	aliases := map[string]string{}
+	for _, project := range []string{anyProject, projectKey} {
+		for p, table := range a {
+			if !strings.EqualFold(p, project) {
+				continue
+			}
+			for alias, status := range table {
+				aliases[strings.ToLower(alias)] = status
+			}
+		}
+	}
+	return aliases
+}
+
+// Resolve returns the status name an alias stands for in a project,
+// or name unchanged if it is not an alias there.
+func (a StatusAliases) Resolve(projectKey, name string) string {
+	if status, ok := a.For(projectKey)[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return status
+	}
+	return name
+}
+
+// ProjectKey returns the project part of an issue key like TEAM-1234
+func ProjectKey(issueKey string) string {
+	if i := strings.LastIndex(issueKey, "-"); i > 0 {
+		return strings.ToUpper(issueKey[:i])
+	}
+	return strings.ToUpper(issueKey)
+}
+
+var issueKeyRe = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9]*-[1-9][0-9]*$`)
+
+// IsIssueKey reports whether s is an issue key like TEAM-1234
+func IsIssueKey(s string) bool {
+	return issueKeyRe.MatchString(s)
 }
 
 // ReadConfigFromFile returns an error if file does not exist
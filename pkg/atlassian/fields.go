@@ -0,0 +1,162 @@
+package atlassian
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+// fieldCacheTTL is how long the field metadata is trusted before it is fetched again
+const fieldCacheTTL = 24 * time.Hour
+
+// richTextType is the custom field type for multi-line text with Jira markup
+const richTextType = "com.atlassian.jira.plugin.system.customfieldtypes:textarea"
+
+// FieldNames maps project keys to the fields wti shows for their issues.
+// The "*" project holds the fields for every other project.
+type FieldNames map[string][]string
+
+// For returns the field names to show for a project's issues
+func (f FieldNames) For(projectKey string) []string {
+	for p, names := range f {
+		if strings.EqualFold(p, projectKey) {
+			return names
+		}
+	}
+	return f[anyProject]
+}
+
+// GetFields returns the metadata for every field in Jira. It is cached at
+// cachePath for a day, since it rarely changes and is slow to fetch.
+func GetFields(jiraClient *jira.Client, cachePath string) ([]jira.Field, error) {
+	if info, err := os.Stat(cachePath); err == nil && time.Since(info.ModTime()) < fieldCacheTTL {
+		if data, err := ioutil.ReadFile(cachePath); err == nil {
+			var fields []jira.Field
+			if err := json.Unmarshal(data, &fields); err == nil {
+				return fields, nil
+			}
+		}
+	}
+
+	fields, _, err := jiraClient.Field.GetList()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get fields: %w", err)
+	}
+	if data, err := json.Marshal(fields); err == nil {
+		// a stale or missing cache only costs us a request next time
+		_ = ioutil.WriteFile(cachePath, data, 0o600)
+	}
+	return fields, nil
+}
+
+// ResolveFields finds the fields with the given names or IDs, like
+// "Story Points" or "customfield_10016", in the order given.
+func ResolveFields(fields []jira.Field, names []string) ([]jira.Field, error) {
+	var resolved []jira.Field
+	var unknown []string
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		found := false
+		for _, f := range fields {
+			if f.ID == name || strings.EqualFold(f.Name, name) {
+				resolved = append(resolved, f)
+				found = true
+				break
+			}
+		}
+		if !found {
+			unknown = append(unknown, fmt.Sprintf("%q", name))
+		}
+	}
+	if len(unknown) > 0 {
+		return resolved, fmt.Errorf("unknown fields %s", strings.Join(unknown, ", "))
+	}
+	return resolved, nil
+}
+
+// IsRichText reports whether a field holds Jira markup, which is better
+// shown as a section of its own than inline.
+func IsRichText(field jira.Field) bool {
+	switch field.ID {
+	case "description", "environment":
+		return true
+	}
+	return field.Schema.Custom == richTextType
+}
+
+// FieldValues returns every field of an issue by ID, as Jira sent them,
+// whether go-jira knows the field or not.
+func FieldValues(issue *jira.Issue) (map[string]interface{}, error) {
+	raw, err := json.Marshal(issue.Fields)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]interface{}
+	err = json.Unmarshal(raw, &values)
+	return values, err
+}
+
+// FormatFieldValue renders a field's value as Github Markdown,
+// according to the field's type.
+func FormatFieldValue(jiraClient *jira.Client, field jira.Field, value interface{}) string {
+	if value == nil {
+		return "None"
+	}
+	if IsRichText(field) {
+		if s, ok := value.(string); ok {
+			return JiraMarkupToGithubMarkdown(jiraClient, s)
+		}
+	}
+	return formatValue(value)
+}
+
+// formatValue renders numbers, users, options and lists of them
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "None"
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []interface{}:
+		if len(v) == 0 {
+			return "None"
+		}
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = formatValue(item)
+		}
+		return strings.Join(items, ", ")
+	case map[string]interface{}:
+		// users, options, versions, components and the like
+		for _, key := range []string{"displayName", "value", "name", "key"} {
+			if s, ok := v[key].(string); ok && s != "" {
+				// cascading selects have their child option nested inside
+				if child, ok := v["child"]; ok {
+					return s + " / " + formatValue(child)
+				}
+				return s
+			}
+		}
+		if id, ok := v["id"]; ok {
+			return formatValue(id)
+		}
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(raw)
+}
@@ -0,0 +1,210 @@
+package atlassian
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+// HistoryEvent is one field changing on an issue
+type HistoryEvent struct {
+	Time   time.Time `json:"time"`
+	Author string    `json:"author"`
+	Field  string    `json:"field"`
+	From   string    `json:"from"`
+	To     string    `json:"to"`
+}
+
+// StatusTime is how long an issue has spent in a status, all told
+type StatusTime struct {
+	Status   string        `json:"status"`
+	Duration time.Duration `json:"-"`
+	Seconds  int64         `json:"seconds"`
+}
+
+// changelogPageSize is how many changes we ask Jira for at a time
+const changelogPageSize = 100
+
+// changelogPage is part of an issue's changelog. Expanding an issue's
+// changelog gives its first page as histories, and the changelog endpoint
+// gives later ones as values.
+type changelogPage struct {
+	StartAt   int                     `json:"startAt"`
+	Total     int                     `json:"total"`
+	IsLast    bool                    `json:"isLast"`
+	Histories []jira.ChangelogHistory `json:"histories"`
+	Values    []jira.ChangelogHistory `json:"values"`
+}
+
+// GetIssueHistory fetches an issue with its changelog, along with just the
+// fields needed to work out how long it spent in each status. Jira only
+// expands the first 100 changes, so the rest are fetched a page at a time.
+func GetIssueHistory(jiraClient *jira.Client, issueKey string) (*jira.Issue, error) {
+	var raw json.RawMessage
+	err := getJSON(jiraClient,
+		fmt.Sprintf("rest/api/2/issue/%s?expand=changelog&fields=created,status", url.PathEscape(issueKey)),
+		&raw)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get history for %s: %w", issueKey, err)
+	}
+	issue := new(jira.Issue)
+	var expanded struct {
+		Changelog changelogPage `json:"changelog"`
+	}
+	if err := json.Unmarshal(raw, issue); err != nil {
+		return nil, fmt.Errorf("unable to read history for %s: %w", issueKey, err)
+	}
+	if err := json.Unmarshal(raw, &expanded); err != nil {
+		return nil, fmt.Errorf("unable to read history for %s: %w", issueKey, err)
+	}
+	if issue.Changelog == nil || expanded.Changelog.Total <= len(issue.Changelog.Histories) {
+		return issue, nil
+	}
+
+	histories, err := getChangelog(jiraClient, issueKey)
+	if err != nil {
+		// older Jira servers have no changelog endpoint
+		fmt.Fprintf(os.Stderr, "Only the first %d of %d changes to %s are shown: %v\n",
+			len(issue.Changelog.Histories), expanded.Changelog.Total, issueKey, err)
+		return issue, nil
+	}
+	issue.Changelog.Histories = histories
+	return issue, nil
+}
+
+// getChangelog pages through all of an issue's changelog
+func getChangelog(jiraClient *jira.Client, issueKey string) ([]jira.ChangelogHistory, error) {
+	var histories []jira.ChangelogHistory
+	for {
+		var page changelogPage
+		err := getJSON(jiraClient, fmt.Sprintf("rest/api/2/issue/%s/changelog?startAt=%d&maxResults=%d",
+			url.PathEscape(issueKey), len(histories), changelogPageSize), &page)
+		if err != nil {
+			return nil, err
+		}
+		histories = append(histories, page.Values...)
+		if page.IsLast || len(page.Values) == 0 || len(histories) >= page.Total {
+			return histories, nil
+		}
+	}
+}
+
+// HistoryEvents lists every change in an issue's changelog, oldest first
+func HistoryEvents(issue *jira.Issue) []HistoryEvent {
+	if issue.Changelog == nil {
+		return nil
+	}
+	var events []HistoryEvent
+	for _, h := range issue.Changelog.Histories {
+		when, err := ParseTime(h.Created)
+		if err != nil {
+			continue
+		}
+		author := h.Author.DisplayName
+		if author == "" {
+			author = "Automation"
+		}
+		for _, item := range h.Items {
+			events = append(events, HistoryEvent{
+				Time:   when,
+				Author: author,
+				Field:  item.Field,
+				From:   item.FromString,
+				To:     item.ToString,
+			})
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time.Before(events[j].Time) })
+	return events
+}
+
+// FilterHistory keeps the events for the given fields, or all of them if none are given
+func FilterHistory(events []HistoryEvent, fields []string) []HistoryEvent {
+	if len(fields) == 0 {
+		return events
+	}
+	var filtered []HistoryEvent
+	for _, e := range events {
+		for _, f := range fields {
+			if strings.EqualFold(e.Field, f) {
+				filtered = append(filtered, e)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// TimeInStatus works out how long an issue has spent in each status, from
+// when it was created until now, in the order it first reached them.
+func TimeInStatus(issue *jira.Issue, events []HistoryEvent, now time.Time) []StatusTime {
+	statusEvents := FilterHistory(events, []string{"status"})
+
+	current := ""
+	if len(statusEvents) > 0 {
+		current = statusEvents[0].From
+	} else if issue.Fields != nil && issue.Fields.Status != nil {
+		current = issue.Fields.Status.Name
+	}
+	since := now
+	if issue.Fields != nil && !time.Time(issue.Fields.Created).IsZero() {
+		since = time.Time(issue.Fields.Created)
+	} else if len(statusEvents) > 0 {
+		// without a creation date, we can't know how long it sat in the first status
+		since = statusEvents[0].Time
+	}
+
+	var times []StatusTime
+	add := func(status string, d time.Duration) {
+		for i := range times {
+			if times[i].Status == status {
+				times[i].Duration += d
+				times[i].Seconds = int64(times[i].Duration.Seconds())
+				return
+			}
+		}
+		times = append(times, StatusTime{Status: status, Duration: d, Seconds: int64(d.Seconds())})
+	}
+	for _, e := range statusEvents {
+		add(current, e.Time.Sub(since))
+		current, since = e.To, e.Time
+	}
+	if current != "" {
+		add(current, now.Sub(since))
+	}
+	return times
+}
+
+// FormatDuration shows a duration the way people talk about ticket ages, like 3d 4h
+func FormatDuration(d time.Duration) string {
+	if d < time.Minute {
+		return "<1m"
+	}
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	default:
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
+
+// ParseTime parses the timestamps Jira uses in comments and changelogs
+func ParseTime(s string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02T15:04:05.999-0700", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized Jira time %q", s)
+}
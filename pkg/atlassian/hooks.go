@@ -0,0 +1,126 @@
+package atlassian
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+// Hook is a local command to run around transitions to a status.
+// Pre commands run before the transition, and if they fail the issue is
+// left alone. Post commands run after the transition succeeds.
+type Hook struct {
+	// Project is the project key the hook applies to. Empty or "*" means all.
+	Project string `json:"project,omitempty" mapstructure:"project"`
+	Status  string `json:"status"            mapstructure:"status"`
+	Pre     string `json:"pre,omitempty"     mapstructure:"pre"`
+	Post    string `json:"post,omitempty"    mapstructure:"post"`
+}
+
+// Hooks are all the configured transition hooks
+type Hooks []Hook
+
+// hookContext is what hooks are told about the transition, as environment variables
+type hookContext struct {
+	issueKey  string
+	project   string
+	summary   string
+	oldStatus string
+	newStatus string
+	dryRun    bool
+}
+
+func newHookContext(issue *jira.Issue, issueKey, projectKey, newStatus string, dryRun bool) hookContext {
+	return hookContext{
+		issueKey:  issueKey,
+		project:   projectKey,
+		summary:   issue.Fields.Summary,
+		oldStatus: issue.Fields.Status.Name,
+		newStatus: newStatus,
+		dryRun:    dryRun,
+	}
+}
+
+func (c hookContext) environ() []string {
+	return append(os.Environ(),
+		"JT_ISSUE_KEY="+c.issueKey,
+		"JT_PROJECT="+c.project,
+		"JT_SUMMARY="+c.summary,
+		"JT_OLD_STATUS="+c.oldStatus,
+		"JT_NEW_STATUS="+c.newStatus,
+	)
+}
+
+// runPre runs the pre hooks for this transition, stopping at the first failure
+func (h Hooks) runPre(c hookContext) error {
+	for _, hook := range h.matching(c) {
+		if hook.Pre == "" {
+			continue
+		}
+		if err := runHook("pre", hook.Pre, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runPost runs the post hooks for this transition. The transition has already
+// happened, so a failure is only worth reporting.
+func (h Hooks) runPost(c hookContext) {
+	for _, hook := range h.matching(c) {
+		if hook.Post == "" {
+			continue
+		}
+		if err := runHook("post", hook.Post, c); err != nil {
+			fmt.Println(err)
+		}
+	}
+}
+
+func (h Hooks) matching(c hookContext) []Hook {
+	var hooks []Hook
+	for _, hook := range h {
+		if hook.Project != "" && hook.Project != anyProject &&
+			!strings.EqualFold(hook.Project, c.project) {
+			continue
+		}
+		if !strings.EqualFold(hook.Status, c.newStatus) {
+			continue
+		}
+		hooks = append(hooks, hook)
+	}
+	return hooks
+}
+
+// runHook runs a command through the shell, showing its output once it's done
+func runHook(stage, command string, c hookContext) error {
+	if c.dryRun {
+		fmt.Printf("dry run, not running %s hook for %s: %s\n", stage, c.issueKey, command)
+		return nil
+	}
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	cmd.Env = c.environ()
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	err := cmd.Run()
+	if err != nil {
+		return fmt.Errorf("%s hook for %s failed (%s): %v\n%s",
+			stage, c.issueKey, command, err, out.String())
+	}
+	if out.Len() > 0 {
+		fmt.Print(out.String())
+	}
+	return nil
+}
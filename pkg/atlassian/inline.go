@@ -0,0 +1,262 @@
+package atlassian
+
+import (
+	"strings"
+	"unicode"
+)
+
+// marks are the characters that wrap text to style it, like *bold*
+var marks = map[rune]NodeKind{
+	'*': StrongNode,
+	'_': EmphasisNode,
+	'-': StrikeNode,
+	'+': InsertNode,
+	'^': SuperscriptNode,
+	'~': SubscriptNode,
+}
+
+// parseInline parses the text of a paragraph, heading, list item or table cell
+func parseInline(text string) []*Node {
+	return (&inlineParser{src: []rune(text)}).parse()
+}
+
+type inlineParser struct {
+	src   []rune
+	nodes []*Node
+	text  strings.Builder
+}
+
+func (p *inlineParser) parse() []*Node {
+	for i := 0; i < len(p.src); {
+		i = p.next(i)
+	}
+	p.flush()
+	return p.nodes
+}
+
+// flush turns any text gathered so far into a text node
+func (p *inlineParser) flush() {
+	if p.text.Len() > 0 {
+		p.nodes = append(p.nodes, &Node{Kind: TextNode, Text: p.text.String()})
+		p.text.Reset()
+	}
+}
+
+func (p *inlineParser) add(node *Node) {
+	p.flush()
+	p.nodes = append(p.nodes, node)
+}
+
+// next parses whatever starts at i, returning where the next thing starts
+func (p *inlineParser) next(i int) int {
+	src := p.src
+	r := src[i]
+	switch {
+	case r == '\n':
+		p.add(&Node{Kind: LineBreakNode})
+		return i + 1
+	case r == '\\' && i+1 < len(src):
+		if src[i+1] == '\\' {
+			p.add(&Node{Kind: LineBreakNode})
+			return i + 2
+		}
+		if unicode.IsPunct(src[i+1]) || unicode.IsSymbol(src[i+1]) {
+			p.add(&Node{Kind: TextNode, Text: string(src[i+1]), Escaped: true})
+			return i + 2
+		}
+	case r == '{':
+		if end := p.macro(i); end > i {
+			return end
+		}
+	case r == '[':
+		if end := p.link(i); end > i {
+			return end
+		}
+	case r == '!':
+		if end := p.image(i); end > i {
+			return end
+		}
+	case r == 'h' && (i == 0 || !isWordRune(src[i-1])):
+		if end := p.bareURL(i); end > i {
+			return end
+		}
+	case r == '?' && i+1 < len(src) && src[i+1] == '?':
+		if end := p.mark(i, "??", CitationNode); end > i {
+			return end
+		}
+	default:
+		if kind, ok := marks[r]; ok {
+			if end := p.mark(i, string(r), kind); end > i {
+				return end
+			}
+		}
+	}
+	p.text.WriteRune(r)
+	return i + 1
+}
+
+// macro handles {{monospace}} and drops {color} tags, which Markdown can't show
+func (p *inlineParser) macro(i int) int {
+	rest := string(p.src[i:])
+	if strings.HasPrefix(rest, "{{") {
+		end := strings.Index(rest[2:], "}}")
+		if end <= 0 || strings.Contains(rest[2:2+end], "\n") {
+			return i
+		}
+		p.add(&Node{Kind: MonospaceNode, Text: rest[2 : 2+end]})
+		return i + len([]rune(rest[:end+4]))
+	}
+	if strings.HasPrefix(rest, "{color") {
+		end := strings.IndexByte(rest, '}')
+		tag := rest[:end+1]
+		if end > 0 && (tag == "{color}" || strings.HasPrefix(tag, "{color:")) {
+			return i + len([]rune(tag))
+		}
+	}
+	return i
+}
+
+// link handles [http://url], [text|http://url] and [~accountid:mention]
+func (p *inlineParser) link(i int) int {
+	end := p.closing(i+1, ']')
+	if end < 0 {
+		return i
+	}
+	inner := string(p.src[i+1 : end])
+	if strings.TrimSpace(inner) == "" {
+		return i
+	}
+	switch {
+	case strings.HasPrefix(inner, "~accountid:"):
+		p.add(&Node{Kind: MentionNode, Text: strings.TrimPrefix(inner, "~accountid:")})
+	case strings.HasPrefix(inner, "~"):
+		p.add(&Node{Kind: MentionNode, Text: strings.TrimPrefix(inner, "~")})
+	case strings.Contains(inner, "|"):
+		bar := strings.Index(inner, "|")
+		url := inner[bar+1:]
+		// a third part is a tooltip, which Markdown has no room for
+		if tip := strings.Index(url, "|"); tip >= 0 {
+			url = url[:tip]
+		}
+		p.add(&Node{
+			Kind:     LinkNode,
+			URL:      strings.TrimSpace(url),
+			Children: parseInline(strings.TrimSpace(inner[:bar])),
+		})
+	default:
+		p.add(&Node{Kind: LinkNode, URL: strings.TrimSpace(inner)})
+	}
+	return end + 1
+}
+
+// bareURL handles URLs in text, like http://example.com/*x*, so the
+// marks in them aren't taken for styling
+func (p *inlineParser) bareURL(i int) int {
+	rest := string(p.src[i:])
+	if !strings.HasPrefix(rest, "http://") && !strings.HasPrefix(rest, "https://") {
+		return i
+	}
+	end := strings.IndexFunc(rest, func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune("|[]<>", r)
+	})
+	if end < 0 {
+		end = len(rest)
+	}
+	// punctuation after a URL usually ends the sentence it's in
+	url := strings.TrimRight(rest[:end], ".,;:!?)")
+	if strings.HasSuffix(url, "://") {
+		return i
+	}
+	p.add(&Node{Kind: LinkNode, URL: url})
+	return i + len([]rune(url))
+}
+
+// image handles !file.png! and !http://url|width=300,alt=text!
+func (p *inlineParser) image(i int) int {
+	if i > 0 && isWordRune(p.src[i-1]) {
+		return i
+	}
+	s := string(p.src[i:])
+	if !isImageAt(s, 0) {
+		return i
+	}
+	inner := s[1 : 1+strings.IndexByte(s[1:], '!')]
+	node := &Node{Kind: ImageNode, URL: inner}
+	if bar := strings.Index(inner, "|"); bar >= 0 {
+		node.URL = inner[:bar]
+		node.Params = parseImageParams(inner[bar+1:])
+	}
+	p.add(node)
+	return i + len([]rune(inner)) + 2
+}
+
+// parseImageParams parses image parameters like "thumbnail" or "width=300,alt=A cat"
+func parseImageParams(params string) map[string]string {
+	parsed := make(map[string]string)
+	for _, param := range strings.Split(params, ",") {
+		param = strings.TrimSpace(param)
+		if param == "" {
+			continue
+		}
+		if eq := strings.Index(param, "="); eq >= 0 {
+			parsed[strings.TrimSpace(param[:eq])] = strings.TrimSpace(param[eq+1:])
+		} else {
+			parsed[param] = ""
+		}
+	}
+	return parsed
+}
+
+// mark handles text wrapped in a mark, like *bold* or ??citation??. Like
+// Jira, marks only count at the edges of words, so hyphenated-words and
+// snake_case stay as they are.
+func (p *inlineParser) mark(i int, mark string, kind NodeKind) int {
+	src := p.src
+	n := len([]rune(mark))
+	start := i + n
+	if i > 0 && isWordRune(src[i-1]) {
+		return i
+	}
+	if start >= len(src) || unicode.IsSpace(src[start]) || string(src[start]) == mark[:1] {
+		return i
+	}
+	for j := start + 1; j+n <= len(src); j++ {
+		if src[j] == '\n' {
+			return i
+		}
+		if src[j] == '{' && j+1 < len(src) && src[j+1] == '{' {
+			// nothing inside monospace can close a mark
+			if end := strings.Index(string(src[j:]), "}}"); end > 0 {
+				j += len([]rune(string(src[j:])[:end+1]))
+				continue
+			}
+		}
+		if string(src[j:j+n]) != mark || unicode.IsSpace(src[j-1]) {
+			continue
+		}
+		if j+n < len(src) && (isWordRune(src[j+n]) || string(src[j+n]) == mark[:1]) {
+			continue
+		}
+		p.add(&Node{Kind: kind, Children: parseInline(string(src[start:j]))})
+		return j + n
+	}
+	return i
+}
+
+// closing finds the rune that closes a bracket opened before from,
+// on the same line, or -1 if there isn't one.
+func (p *inlineParser) closing(from int, close rune) int {
+	for j := from; j < len(p.src); j++ {
+		switch p.src[j] {
+		case close:
+			return j
+		case '\n':
+			return -1
+		}
+	}
+	return -1
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
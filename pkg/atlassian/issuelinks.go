@@ -0,0 +1,179 @@
+package atlassian
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+// IssueKeyPattern matches issue keys in text, like TEAM-1234. Unlike the
+// branch names trimJira reads, keys in text must be in capitals, so that
+// words like x-1 aren't taken for them. Things like UTF-8 still match, so
+// an IssueLinker using it only links keys it knows are issues.
+const IssueKeyPattern = `[A-Z][A-Z0-9]{1,9}-[1-9][0-9]{0,6}`
+
+// IssueLinker links issue keys in converted text to the issues, like
+// [TEAM-1234](https://example.atlassian.net/browse/TEAM-1234), and makes
+// links relative to the Jira site absolute.
+type IssueLinker struct {
+	// Host is the Jira site, like https://example.atlassian.net
+	Host string
+	// Pattern matches issue keys
+	Pattern *regexp.Regexp
+	// Projects are the projects whose keys are always linked, like the
+	// project of the issue being shown
+	Projects []string
+	// Lookup finds an issue's summary, reporting whether the issue exists
+	Lookup func(issueKey string) (summary string, ok bool)
+	// Summaries shows each linked issue's summary, found with Lookup.
+	// Without it, links only show the key.
+	Summaries bool
+	// Tooltip shows summaries as the link's tooltip, instead of after it
+	Tooltip bool
+	// verify means Pattern can match things that aren't keys, so keys
+	// outside Projects are only linked if Lookup finds them
+	verify bool
+}
+
+// NewIssueLinker links issue keys matching pattern to issues on host.
+// An empty pattern means IssueKeyPattern, which only links keys in
+// Projects or found by Lookup.
+func NewIssueLinker(host, pattern string) (*IssueLinker, error) {
+	verify := pattern == ""
+	if verify {
+		pattern = IssueKeyPattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid issue key pattern %q: %v", pattern, err)
+	}
+	return &IssueLinker{Host: strings.TrimRight(host, "/"), Pattern: re, verify: verify}, nil
+}
+
+// IssueURL is where an issue is shown on the Jira site
+func (l *IssueLinker) IssueURL(issueKey string) string {
+	return l.Host + "/browse/" + issueKey
+}
+
+// IsKey reports whether all of s is an issue key
+func (l *IssueLinker) IsKey(s string) bool {
+	loc := l.Pattern.FindStringIndex(s)
+	return loc != nil && loc[0] == 0 && loc[1] == len(s)
+}
+
+// Resolve returns where a link goes: links that are issue keys go to
+// the issue, and paths go to that page of the Jira site.
+func (l *IssueLinker) Resolve(link string) string {
+	switch {
+	case l.IsKey(link):
+		return l.IssueURL(link)
+	case strings.HasPrefix(link, "/") && !strings.HasPrefix(link, "//"):
+		return l.Host + link
+	}
+	return link
+}
+
+// markdownText escapes the characters in plain text that Markdown would
+// read as styling, like the * in a summary
+var markdownText = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`,
+	`<`, `\<`, `>`, `\>`, `~`, `\~`)
+
+// link renders a Markdown link to an issue, with its summary if we have
+// one. In tables, bars in the summary are escaped so they don't end the cell.
+func (l *IssueLinker) link(issueKey string, inTable bool) string {
+	summary := ""
+	if l.Summaries && l.Lookup != nil {
+		summary, _ = l.Lookup(issueKey)
+	}
+	link := "[" + issueKey + "](" + l.IssueURL(issueKey)
+	switch {
+	case summary == "":
+		return link + ")"
+	case l.Tooltip:
+		summary = strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(summary)
+	default:
+		summary = markdownText.Replace(summary)
+	}
+	if inTable {
+		summary = strings.ReplaceAll(summary, "|", `\|`)
+	}
+	if l.Tooltip {
+		return link + ` "` + summary + `")`
+	}
+	return link + ") (" + summary + ")"
+}
+
+// linkKeys replaces the issue keys in text with links to them. Keys only
+// count on their own, not as part of words, paths or longer keys.
+func (l *IssueLinker) linkKeys(text string, inTable bool) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range l.Pattern.FindAllStringIndex(text, -1) {
+		if loc[0] > 0 {
+			r, _ := utf8.DecodeLastRuneInString(text[:loc[0]])
+			if isWordRune(r) || strings.ContainsRune("-_/.", r) {
+				continue
+			}
+		}
+		if loc[1] < len(text) {
+			r, _ := utf8.DecodeRuneInString(text[loc[1]:])
+			if isWordRune(r) || strings.ContainsRune("-_/", r) {
+				continue
+			}
+		}
+		issueKey := text[loc[0]:loc[1]]
+		if !l.isIssue(issueKey) {
+			continue
+		}
+		b.WriteString(text[last:loc[0]])
+		b.WriteString(l.link(issueKey, inTable))
+		last = loc[1]
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// isIssue reports whether a key found in text should be linked
+func (l *IssueLinker) isIssue(issueKey string) bool {
+	if !l.verify {
+		return true
+	}
+	for _, project := range l.Projects {
+		if ProjectKey(issueKey) == project {
+			return true
+		}
+	}
+	if l.Lookup == nil {
+		return false
+	}
+	_, ok := l.Lookup(issueKey)
+	return ok
+}
+
+// IssueLookup finds issues for IssueLinker.Lookup, remembering them so
+// each issue is only fetched once. Issues that can't be fetched are
+// remembered as missing. Most keys checked in text aren't issues, so use
+// a client from GetQuietJIRAClient that doesn't log them.
+func IssueLookup(jiraClient *jira.Client) func(string) (string, bool) {
+	type found struct {
+		summary string
+		ok      bool
+	}
+	issues := make(map[string]found)
+	return func(issueKey string) (string, bool) {
+		if f, ok := issues[issueKey]; ok {
+			return f.summary, f.ok
+		}
+		var f found
+		issue, _, err := jiraClient.Issue.Get(issueKey, &jira.GetQueryOptions{Fields: "summary"})
+		if err == nil && issue != nil && issue.Fields != nil {
+			f = found{summary: issue.Fields.Summary, ok: true}
+		}
+		issues[issueKey] = f
+		return f.summary, f.ok
+	}
+}
@@ -0,0 +1,154 @@
+package atlassian
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+func TestIssueLinkSummaries(t *testing.T) {
+	summaries := map[string]string{"TEAM-1": `Fix *all* the [things] | "now"`}
+	tests := []struct {
+		name    string
+		markup  string
+		tooltip bool
+		want    string
+	}{
+		{
+			name:   "text",
+			markup: "see TEAM-1",
+			want:   `see [TEAM-1](https://example.atlassian.net/browse/TEAM-1) (Fix \*all\* the \[things\] | "now")`,
+		},
+		{
+			name:   "text in a table",
+			markup: "|TEAM-1|",
+			want: "| [TEAM-1](https://example.atlassian.net/browse/TEAM-1) " +
+				`(Fix \*all\* the \[things\] \| "now") |` + "\n| --- |",
+		},
+		{
+			name:    "tooltip",
+			markup:  "[TEAM-1]",
+			tooltip: true,
+			want:    `[TEAM-1](https://example.atlassian.net/browse/TEAM-1 "Fix *all* the [things] | \"now\"")`,
+		},
+		{
+			name:    "tooltip in a table",
+			markup:  "|TEAM-1|",
+			tooltip: true,
+			want: "| [TEAM-1](https://example.atlassian.net/browse/TEAM-1 " +
+				`"Fix *all* the [things] \| \"now\"") |` + "\n| --- |",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			linker, err := NewIssueLinker("https://example.atlassian.net/", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			linker.Lookup = func(issueKey string) (string, bool) {
+				summary, ok := summaries[issueKey]
+				return summary, ok
+			}
+			linker.Summaries = true
+			linker.Tooltip = tt.tooltip
+			got := RenderMarkdown(ParseJira(tt.markup), MarkdownOptions{Issues: linker})
+			if got != tt.want {
+				t.Errorf("RenderMarkdown(%q) = %s, want %s", tt.markup, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIssueLinkerOnlyLinksIssues(t *testing.T) {
+	text := "TEAM-2 needs OPS-1 and TEAM-1, not UTF-8 or SHA-256"
+	tests := []struct {
+		name    string
+		pattern string
+		want    []string
+	}{
+		{"default pattern", "", []string{"TEAM-2", "OPS-1", "TEAM-1"}},
+		{"configured pattern", "[A-Z]+-[0-9]+", []string{"TEAM-2", "OPS-1", "TEAM-1", "UTF-8", "SHA-256"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			linker, err := NewIssueLinker("https://example.atlassian.net", tt.pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// TEAM-2 is in the issue's project, and TEAM-1 and OPS-1 exist
+			linker.Projects = []string{"TEAM"}
+			linker.Lookup = IssueLookup(testJira(t))
+			got := linker.linkKeys(text, false)
+			var linked []string
+			for _, field := range strings.Fields(got) {
+				if strings.HasPrefix(field, "[") {
+					linked = append(linked, field[1:strings.Index(field, "]")])
+				}
+			}
+			if strings.Join(linked, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("linkKeys(%q) = %s, want links to %q", text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIssueLookup(t *testing.T) {
+	requests := map[string]int{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		issueKey := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		requests[issueKey]++
+		if issueKey != "TEAM-1" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"key": "TEAM-1", "fields": {"summary": "Reliable uploads"}}`))
+	}))
+	defer srv.Close()
+	jiraClient, err := jira.NewClient(nil, srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lookup := IssueLookup(jiraClient)
+	for i := 0; i < 2; i++ {
+		if summary, ok := lookup("TEAM-1"); !ok || summary != "Reliable uploads" {
+			t.Errorf("lookup(TEAM-1) = %q, %v, want %q, true", summary, ok, "Reliable uploads")
+		}
+		if _, ok := lookup("UTF-8"); ok {
+			t.Errorf("lookup(UTF-8) found an issue")
+		}
+	}
+	for issueKey, n := range requests {
+		if n != 1 {
+			t.Errorf("fetched %s %d times, want once", issueKey, n)
+		}
+	}
+}
+
+func TestIssueLookupIsQuiet(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	lookup := IssueLookup(GetQuietJIRAClient(&Config{Host: srv.URL, User: "me", Token: "secret"}))
+
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	_, ok := lookup("UTF-8")
+	os.Stdout = stdout
+	w.Close()
+	printed, _ := ioutil.ReadAll(r)
+
+	if ok {
+		t.Errorf("lookup(UTF-8) found an issue")
+	}
+	if len(printed) > 0 {
+		t.Errorf("lookup(UTF-8) printed %q, want nothing", printed)
+	}
+}
@@ -4,8 +4,9 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"net/http"
+	"os"
 	"regexp"
-	"strconv"
 	"strings"
 	"unicode"
 
@@ -15,9 +16,22 @@ import (
 )
 
 // GetJIRAClient takes a config, and makes a JIRAClient configured
-// to use BasicAuth
+// to use BasicAuth. In a dry run, the client will not send any changes.
 func GetJIRAClient(config *Config) *jira.Client {
-	httpClient := middleware.NewBasicAuthHTTPClient(config.User, config.Token)
+	return newJIRAClient(config, middleware.NewBasicAuthHTTPClient(config.User, config.Token))
+}
+
+// GetQuietJIRAClient is GetJIRAClient without logging failed requests,
+// which would print credentials along with them. It is for lookups where
+// a missing issue is an expected answer, like IssueLookup's.
+func GetQuietJIRAClient(config *Config) *jira.Client {
+	return newJIRAClient(config, middleware.NewQuietBasicAuthHTTPClient(config.User, config.Token))
+}
+
+func newJIRAClient(config *Config, httpClient *http.Client) *jira.Client {
+	if config.DryRun {
+		httpClient.Transport = middleware.NewDryRunRoundTripper(httpClient.Transport, os.Stdout)
+	}
 
 	jiraClient, err := jira.NewClient(httpClient, config.Host)
 	if err != nil {
@@ -53,7 +67,44 @@ func GetIssue(jiraClient *jira.Client, issue string) (*jira.Issue, error) {
 	return jiraIssue, nil
 }
 
-func AssignIssueToSelf(jiraClient *jira.Client, issue *jira.Issue, issueKey string) error {
+// SearchIssues returns every issue matching jql, with just enough
+// fields filled in to transition them.
+func SearchIssues(jiraClient *jira.Client, jql string) ([]jira.Issue, error) {
+	var issues []jira.Issue
+	opts := &jira.SearchOptions{
+		MaxResults: 100,
+		Fields:     []string{"summary", "status", "project", "issuetype", "assignee"},
+	}
+	err := jiraClient.Issue.SearchPages(jql, opts, func(issue jira.Issue) error {
+		issues = append(issues, issue)
+		return nil
+	})
+	return issues, err
+}
+
+// getJSON fetches a Jira REST endpoint that go-jira has no wrapper for,
+// and decodes the response into v.
+func getJSON(jiraClient *jira.Client, apiEndpoint string, v interface{}) error {
+	req, err := jiraClient.NewRequest("GET", apiEndpoint, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := jiraClient.Do(req, v)
+	if err != nil {
+		return jira.NewJiraError(resp, err)
+	}
+	return nil
+}
+
+// AssignIssueToSelf assigns the issue to whoever we are logged in as,
+// recording the change in the journal. In a dry run it only says so.
+func AssignIssueToSelf(
+	jiraClient *jira.Client,
+	issue *jira.Issue,
+	issueKey string,
+	journal *Journal,
+	dryRun bool,
+) error {
 	self, _, selfErr := jiraClient.User.GetSelf()
 	if selfErr != nil {
 		return fmt.Errorf("unable to get myself: %+v", selfErr)
@@ -64,13 +115,49 @@ func AssignIssueToSelf(jiraClient *jira.Client, issue *jira.Issue, issueKey stri
 		if assignErr != nil {
 			return fmt.Errorf("unable to assign %s to yourself: %+v", issueKey, assignErr)
 		}
-		fmt.Printf("Re-Assigned %s from %s\n", issueKey, displayJiraUser(issue.Fields.Assignee))
+		if dryRun {
+			fmt.Printf("Issue %s would be assigned to you, from %s\n",
+				issueKey, DisplayJiraUser(issue.Fields.Assignee))
+			return nil
+		}
+		fmt.Printf("Re-Assigned %s from %s\n", issueKey, DisplayJiraUser(issue.Fields.Assignee))
+		entry := JournalEntry{
+			IssueKey:   issueKey,
+			Action:     JournalAssign,
+			ToAssignee: self.AccountID,
+		}
+		if issue.Fields.Assignee != nil {
+			entry.FromAssignee = issue.Fields.Assignee.AccountID
+		}
+		if err := journal.Record(entry); err != nil {
+			fmt.Printf("Unable to record change in journal: %v\n", err)
+		}
 	} else {
 		fmt.Println("Already assigned to to you")
 	}
 	return nil
 }
 
+// SetAssignee assigns the issue to the given account, or unassigns it
+// if accountID is empty.
+func SetAssignee(jiraClient *jira.Client, issueKey string, accountID string) error {
+	var assignee interface{}
+	if accountID != "" {
+		assignee = accountID
+	}
+	req, err := jiraClient.NewRequest("PUT",
+		fmt.Sprintf("rest/api/2/issue/%s/assignee", issueKey),
+		map[string]interface{}{"accountId": assignee})
+	if err != nil {
+		return err
+	}
+	resp, err := jiraClient.Do(req, nil)
+	if err != nil {
+		return jira.NewJiraError(resp, err)
+	}
+	return nil
+}
+
 // ParseJiraIssueFromBranch - Sanitizes input
 //  + Trims leading "feature/" (or whatever GIT_BRANCH_PREFIX set to)
 //  + Trims leading and trailing whitespace
@@ -109,75 +196,6 @@ func trimJira(s string) string {
 	return result
 }
 
-func MoveIssueToStatusByName(
-	jiraClient *jira.Client,
-	issue *jira.Issue,
-	issueKey string,
-	statusName string,
-) error {
-	originalStatus := issue.Fields.Status.Name
-	if issue.Fields.Status.Name == statusName ||
-		caseInsensitiveContains(issue.Fields.Status.Name, statusName) {
-		return fmt.Errorf("issue is Already in Status %s\n", issue.Fields.Status.Name)
-	}
-
-	err := transitionIssueByStatusName(jiraClient, issueKey, statusName)
-	if err != nil {
-		return err
-	}
-	issue, _, err = jiraClient.Issue.Get(issueKey, nil)
-	if err != nil {
-		return err
-	}
-	fmt.Printf("Issue %s Status successfully changed from: %s and set to: %+v\n",
-		issueKey, originalStatus, issue.Fields.Status.Name)
-
-	return nil
-}
-
-func transitionIssueByStatusName(
-	jiraClient *jira.Client,
-	issueKey string,
-	statusName string,
-) error {
-	var transitionID string
-	possibleTransitions, _, err := jiraClient.Issue.GetTransitions(issueKey)
-	if err != nil {
-		return err
-	}
-	for _, v := range possibleTransitions {
-		if strings.EqualFold(v.To.Name, statusName) {
-			transitionID = v.ID
-			break
-		}
-	}
-	// no exact match, so remove whitespace so that "ToDo" arg will match "TO DO" status
-	if transitionID == "" {
-		for _, v := range possibleTransitions {
-			if strings.EqualFold(removeWhiteSpace(v.To.Name), statusName) {
-				transitionID = v.ID
-				break
-			}
-		}
-	}
-	// still no match, so look for partial, so "Done" arg will match "Deployed / Done"
-	if transitionID == "" {
-		// substring match only if exact match fails
-		for _, v := range possibleTransitions {
-			if caseInsensitiveContains(v.To.Name, statusName) {
-				transitionID = v.ID
-				break
-			}
-		}
-	}
-	// still no match. sigh. give up.
-	if transitionID == "" {
-		return fmt.Errorf("there does not appear to be a valid transition to %s", statusName)
-	}
-	_, err = jiraClient.Issue.DoTransition(issueKey, transitionID)
-	return err
-}
-
 func removeWhiteSpace(str string) string {
 	var b strings.Builder
 	b.Grow(len(str))
@@ -194,219 +212,59 @@ func caseInsensitiveContains(s, substr string) bool {
 	return strings.Contains(s, substr)
 }
 
-// jiration - convenience for Jira Markup to Github Markdown translation rule
-type jiration struct {
-	re   *regexp.Regexp
-	repl interface{}
-}
-
-// JiraToMD - This uses some regular expressions to make a reasonable translation
-// from Jira Markup to Github Markdown. It is not a complete PEG, so it will break down
-// especially for more complicated nested formatting (lists inside of lists).
-// pandoc seems to have different problems. Pick your poison.
-func JiraToMD(str string) string {
-	jirations := []jiration{
-		{ // UnOrdered Lists
-			re: regexp.MustCompile(`(?m)^[ \t]*(\*+)\s+`),
-			repl: func(groups []string) string {
-				_, stars := groups[0], groups[1]
-				return strings.Repeat("  ", len(stars)-1) + "* "
-			},
-		},
-		{ // Ordered Lists
-			re: regexp.MustCompile(`(?m)^[ \t]*(#+)\s+`),
-			repl: func(groups []string) string {
-				_, nums := groups[0], groups[1]
-				return strings.Repeat("  ", len(nums)-1) + "1. "
-			},
-		},
-		{ // Headers 1-6
-			re: regexp.MustCompile(`(?m)^h([0-6])\.(.*)$`),
-			repl: func(groups []string) string {
-				_, level, content := groups[0], groups[1], groups[2]
-				i, _ := strconv.Atoi(level)
-				return strings.Repeat("#", i) + content
-			},
-		},
-		{ // Bold
-			re:   regexp.MustCompile(`\*(\S.*)\*`),
-			repl: "**$1**",
-		},
-		{ // Italic
-			re:   regexp.MustCompile(`\_(\S.*)\_`),
-			repl: "*$1*",
-		},
-		{ // Monospaced text
-			re:   regexp.MustCompile(`\{\{([^}]+)\}\}`),
-			repl: "`$1`",
-		},
-		{ // Citations (buggy)
-			re:   regexp.MustCompile(`\?\?((?:.[^?]|[^?].)+)\?\?`),
-			repl: "<cite>$1</cite>",
-		},
-		{ // Inserts
-			re:   regexp.MustCompile(`\+([^+]*)\+`),
-			repl: "<ins>$1</ins>",
-		},
-		{ // Superscript
-			re:   regexp.MustCompile(`\^([^^]*)\^`),
-			repl: "<sup>$1</sup>",
-		},
-		{ // Subscript
-			re:   regexp.MustCompile(`~([^~]*)~`),
-			repl: "<sub>$1</sub>",
-		},
-		{ // Strikethrough
-			re:   regexp.MustCompile(`(\s+)-(\S+.*?\S)-(\s+)`),
-			repl: "$1~~$2~~$3",
-		},
-		{ // Code Block
-			re: regexp.MustCompile(
-				`\{code(:([a-z]+))?([:|]?(title|borderStyle|borderColor|borderWidth|bgColor|titleBGColor)=.+?)*\}`,
-			),
-			repl: "```$2",
-		},
-		{ // Code Block End
-			re:   regexp.MustCompile(`{code}`),
-			repl: "```",
-		},
-		{ // Pre-formatted text
-			re:   regexp.MustCompile(`{noformat}`),
-			repl: "```",
-		},
-		{ // Un-named Links
-			re:   regexp.MustCompile(`(?U)\[([^|]+)\]`),
-			repl: "<$1>",
-		},
-		{ // Images
-			re:   regexp.MustCompile(`!(.+)!`),
-			repl: "![]($1)",
-		},
-		{ // Named Links
-			re:   regexp.MustCompile(`\[(.+?)\|(.+)\]`),
-			repl: "[$1]($2)",
-		},
-		{ // Single Paragraph Blockquote
-			re:   regexp.MustCompile(`(?m)^bq\.\s+`),
-			repl: "> ",
-		},
-		{ // Remove color: unsupported in md
-			re:   regexp.MustCompile(`(?m)\{color:[^}]+\}(.*)\{color\}`),
-			repl: "$1",
-		},
-		{ // panel into table
-			re: regexp.MustCompile(
-				`(?m)\{panel:title=([^}]*)\}\n?(.*?)\n?\{panel\}`,
-			),
-			repl: "\n| $1 |\n| --- |\n| $2 |",
-		},
-		{ // table header
-			re: regexp.MustCompile(`(?m)^[ \t]*((?:\|\|.*?)+\|\|)[ \t]*$`),
-			repl: func(groups []string) string {
-				_, headers := groups[0], groups[1]
-				reBarred := regexp.MustCompile(`\|\|`)
-
-				singleBarred := reBarred.ReplaceAllString(headers, "|")
-				fillerRe := regexp.MustCompile(`\|[^|]+`)
-				return "\n" + singleBarred + "\n" + fillerRe.ReplaceAllString(
-					singleBarred,
-					"| --- ",
-				)
-			},
-		},
-		{ // remove leading-space of table headers and rows
-			re:   regexp.MustCompile(`(?m)^[ \t]*\|`),
-			repl: "|",
-		},
-	}
-	for _, jiration := range jirations {
-		switch v := jiration.repl.(type) {
-		case string:
-			str = jiration.re.ReplaceAllString(str, v)
-		case func([]string) string:
-			str = replaceAllStringSubmatchFunc(jiration.re, str, v)
-		default:
-			fmt.Printf("I don't know about type %T!\n", v)
-		}
-	}
-	return str
-}
-
 type jiraResolver struct {
 	JiraClient *jira.Client
+	// names are the accounts we've already looked up
+	names map[string]string
 }
 
-// JiraMarkupMentionToEmail will replace JiraMarkup account mentions
-// with Display Name followed by parenthetical email addresses
-func (j *jiraResolver) JiraMarkupMentionToEmail(str string) string {
-	re := regexp.MustCompile(`(?m)(\[~accountid:)([a-zA-Z0-9-:]+)(\])`)
-	rfunc := func(groups []string) string {
-		// groups[0] is initial match
-		accountID := groups[2]
-
+// mention shows a mentioned account as its display name and email,
+// or as it was written if we can't look it up.
+func (j *jiraResolver) mention(accountID string) string {
+	if name, ok := j.names[accountID]; ok {
+		return name
+	}
+	name := "[~accountid:" + accountID + "]"
+	if j.JiraClient != nil {
 		jiraUser, resp, err := j.JiraClient.User.Get(accountID)
-		// if we cannot resolve it, so just leave it as it was
-		if err != nil {
-			return groups[0]
-		}
-		if resp == nil {
-			return groups[0]
-		}
-		if c := resp.StatusCode; c < 200 || c > 299 {
-			return groups[0]
+		if err == nil && resp != nil && resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+			name = DisplayJiraUser(jiraUser)
 		}
-
-		return displayJiraUser(jiraUser)
 	}
-	return replaceAllStringSubmatchFunc(re, str, rfunc)
+	j.names[accountID] = name
+	return name
 }
 
-func displayJiraUser(jiraUser *jira.User) string {
+// DisplayJiraUser shows a user as their display name followed by their email
+func DisplayJiraUser(jiraUser *jira.User) string {
+	if jiraUser == nil {
+		return "Unassigned"
+	}
 	return jiraUser.DisplayName + " (" + jiraUser.EmailAddress + ")"
 }
 
-// replaceAllStringSubmatchFunc - Invokes Callback for Regex Replacement
-// The repl function takes an unusual string slice argument:
-// - The 0th element is the complete match
-// - The following slice elements are the nth string found
-// by a parenthesized capture group (including named capturing groups)
-//
-// This is a Go implementation to match other languages:
-// PHP: preg_replace_callback($pattern, $callback, $subject)
-// Ruby: subject.gsub(pattern) {|match| callback}
-// Python: re.sub(pattern, callback, subject)
-// JavaScript: subject.replace(pattern, callback)
-// See https://gist.github.com/elliotchance/d419395aa776d632d897
-func replaceAllStringSubmatchFunc(
-	re *regexp.Regexp,
-	str string,
-	repl func([]string) string,
-) string {
-	result := ""
-	lastIndex := 0
-
-	for _, v := range re.FindAllSubmatchIndex([]byte(str), -1) {
-		groups := []string{}
-		for i := 0; i < len(v); i += 2 {
-			if v[i] == -1 || v[i+1] == -1 {
-				// if the group is not found, avoid possible error
-				groups = append(groups, "")
-			} else {
-				groups = append(groups, str[v[i]:v[i+1]])
-			}
-		}
-
-		result += str[lastIndex:v[0]] + repl(groups)
-		lastIndex = v[1]
-	}
+// JiraMarkupToGithubMarkdown translates Jira wiki markup to Github Markdown,
+// showing mentioned users by name and email.
+func JiraMarkupToGithubMarkdown(jiraClient *jira.Client, str string) string {
+	return IssueMarkupToGithubMarkdown(jiraClient, str, MarkdownOptions{})
+}
 
-	return result + str[lastIndex:]
+// IssueMarkupToGithubMarkdown is JiraMarkupToGithubMarkdown for markup from
+// an issue, with opts to show its images and link the issues it mentions.
+// Mentioned users are looked up unless opts says how to show them.
+func IssueMarkupToGithubMarkdown(jiraClient *jira.Client, str string, opts MarkdownOptions) string {
+	return IssueDocumentToGithubMarkdown(jiraClient, ParseJira(str), opts)
 }
 
-func JiraMarkupToGithubMarkdown(jiraClient *jira.Client, str string) string {
-	jiraAccountResolver := jiraResolver{
-		JiraClient: jiraClient,
+// IssueDocumentToGithubMarkdown is IssueMarkupToGithubMarkdown for rich
+// text we already have as a document, like the ones from GetIssueV3.
+func IssueDocumentToGithubMarkdown(jiraClient *jira.Client, doc *Node, opts MarkdownOptions) string {
+	if opts.Mention == nil {
+		jiraAccountResolver := jiraResolver{
+			JiraClient: jiraClient,
+			names:      make(map[string]string),
+		}
+		opts.Mention = jiraAccountResolver.mention
 	}
-	resolved := jiraAccountResolver.JiraMarkupMentionToEmail(str)
-	return JiraToMD(resolved)
+	return RenderMarkdown(doc, opts)
 }
@@ -0,0 +1,47 @@
+package atlassian
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+func TestAssignIssueToSelfDryRun(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// a dry run client answers writes itself, with nothing
+		if r.Method == http.MethodGet {
+			w.Write([]byte(`{"accountId": "me"}`))
+		}
+	}))
+	defer srv.Close()
+	jiraClient, err := jira.NewClient(nil, srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issue := &jira.Issue{Key: "TEAM-1", Fields: &jira.IssueFields{
+		Assignee: &jira.User{AccountID: "them", DisplayName: "Them", EmailAddress: "them@example.com"},
+	}}
+
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	err = AssignIssueToSelf(jiraClient, issue, "TEAM-1", nil, true)
+	os.Stdout = stdout
+	w.Close()
+	printed, _ := ioutil.ReadAll(r)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Issue TEAM-1 would be assigned to you, from Them (them@example.com)\n"
+	if string(printed) != want {
+		t.Errorf("AssignIssueToSelf printed %q, want %q", printed, want)
+	}
+}
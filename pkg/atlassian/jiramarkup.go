@@ -0,0 +1,266 @@
+package atlassian
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// taskBoxes are how checkbox list items are written in Jira, which has no task lists
+const (
+	taskDone = "☑"
+	taskTodo = "☐"
+)
+
+// MarkdownToJira translates Github Markdown to Jira wiki markup, by
+// parsing it into a document and rendering that.
+func MarkdownToJira(md string) string {
+	return RenderJira(ParseMarkdown(md))
+}
+
+// RenderJira renders a document as Jira wiki markup
+func RenderJira(doc *Node) string {
+	r := &jiraRenderer{}
+	return r.blocks(doc.Children)
+}
+
+type jiraRenderer struct {
+	// inTable is set while rendering table cells, which must stay on one line
+	inTable bool
+}
+
+func (r *jiraRenderer) blocks(nodes []*Node) string {
+	var out []string
+	for _, node := range nodes {
+		out = append(out, r.block(node))
+	}
+	return strings.Join(out, "\n\n")
+}
+
+func (r *jiraRenderer) block(node *Node) string {
+	switch node.Kind {
+	case HeadingNode:
+		return "h" + string(rune('0'+node.Level)) + ". " + r.inlines(node.Children)
+	case RuleNode:
+		return "----"
+	case CodeBlockNode:
+		lang := JiraLanguage(node.Language)
+		macro := node.Macro
+		if macro == "" {
+			// Jira highlights {code} without a language as Java
+			macro = "noformat"
+			if lang != "" {
+				macro = "code"
+			}
+		}
+		open := "{" + macro + "}"
+		if lang != "" && macro == "code" {
+			open = "{code:" + lang + "}"
+		}
+		return open + "\n" + node.Text + "\n{" + macro + "}"
+	case QuoteNode:
+		body := r.blocks(node.Children)
+		if len(node.Children) == 1 && node.Children[0].Kind == ParagraphNode &&
+			!strings.Contains(body, "\n") {
+			return "bq. " + body
+		}
+		return "{quote}\n" + body + "\n{quote}"
+	case PanelNode:
+		return "{" + node.Macro + macroParams(node.Params) + "}\n" +
+			r.blocks(node.Children) + "\n{" + node.Macro + "}"
+	case ListNode:
+		return r.list(node, "")
+	case TableNode:
+		return r.table(node)
+	case ParagraphNode:
+		return r.inlines(node.Children)
+	default:
+		return r.inline(node)
+	}
+}
+
+// macroParams writes macro parameters like ":title=Notes|borderStyle=solid"
+func macroParams(params map[string]string) string {
+	if len(params) == 0 {
+		return ""
+	}
+	var names []string
+	for name := range params {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	var parts []string
+	if lead, ok := params[""]; ok {
+		parts = append(parts, lead)
+	}
+	for _, name := range names {
+		if params[name] == "" {
+			parts = append(parts, name)
+		} else {
+			parts = append(parts, name+"="+params[name])
+		}
+	}
+	return ":" + strings.Join(parts, "|")
+}
+
+// list renders a list with Jira's bullets, where nesting is shown by
+// repeating them, like "#*" for a bullet inside a numbered list.
+func (r *jiraRenderer) list(node *Node, markers string) string {
+	marker := "*"
+	if node.Ordered {
+		marker = "#"
+	}
+	markers += marker
+	var lines []string
+	for _, item := range node.Children {
+		var text []string
+		var nested []string
+		for _, child := range item.Children {
+			if child.Kind == ListNode {
+				nested = append(nested, r.list(child, markers))
+				continue
+			}
+			// Jira list items are one line, so breaks have to be forced
+			text = append(text, strings.ReplaceAll(r.block(child), "\n", " \\\\ "))
+		}
+		prefix := markers + " "
+		if item.Task {
+			box := taskTodo
+			if item.Checked {
+				box = taskDone
+			}
+			prefix += box + " "
+		}
+		lines = append(lines, prefix+strings.Join(text, " \\\\ "))
+		lines = append(lines, nested...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (r *jiraRenderer) table(node *Node) string {
+	r.inTable = true
+	defer func() { r.inTable = false }()
+
+	var lines []string
+	for _, row := range node.Children {
+		var b strings.Builder
+		for _, cell := range row.Children {
+			bar := "|"
+			if cell.Header {
+				bar = "||"
+			}
+			text := r.inlines(cell.Children)
+			if text == "" {
+				// Jira would read an empty cell as a header bar
+				text = " "
+			}
+			b.WriteString(bar + text)
+		}
+		if len(row.Children) > 0 && row.Children[len(row.Children)-1].Header {
+			b.WriteString("||")
+		} else {
+			b.WriteString("|")
+		}
+		lines = append(lines, b.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (r *jiraRenderer) inlines(nodes []*Node) string {
+	var b strings.Builder
+	for _, node := range nodes {
+		b.WriteString(r.inline(node))
+	}
+	return b.String()
+}
+
+func (r *jiraRenderer) inline(node *Node) string {
+	switch node.Kind {
+	case TextNode:
+		if node.Escaped {
+			if strings.ContainsAny(node.Text, "*_-+^~?{}[]!|\\#") {
+				return "\\" + node.Text
+			}
+			return node.Text
+		}
+		return r.escape(node.Text)
+	case StrongNode:
+		return "*" + r.inlines(node.Children) + "*"
+	case EmphasisNode:
+		return "_" + r.inlines(node.Children) + "_"
+	case StrikeNode:
+		return "-" + r.inlines(node.Children) + "-"
+	case InsertNode:
+		return "+" + r.inlines(node.Children) + "+"
+	case SuperscriptNode:
+		return "^" + r.inlines(node.Children) + "^"
+	case SubscriptNode:
+		return "~" + r.inlines(node.Children) + "~"
+	case CitationNode:
+		return "??" + r.inlines(node.Children) + "??"
+	case MonospaceNode:
+		text := node.Text
+		if r.inTable {
+			text = strings.ReplaceAll(text, "|", "\\|")
+		}
+		return "{{" + text + "}}"
+	case LinkNode:
+		text := r.inlines(node.Children)
+		if text == "" || text == node.URL {
+			return "[" + node.URL + "]"
+		}
+		return "[" + text + "|" + node.URL + "]"
+	case ImageNode:
+		var params []string
+		var names []string
+		for name := range node.Params {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if node.Params[name] == "" {
+				params = append(params, name)
+			} else {
+				params = append(params, name+"="+node.Params[name])
+			}
+		}
+		if len(params) == 0 {
+			return "!" + node.URL + "!"
+		}
+		return "!" + node.URL + "|" + strings.Join(params, ",") + "!"
+	case MentionNode:
+		return "[~accountid:" + node.Text + "]"
+	case LineBreakNode:
+		if r.inTable {
+			return " \\\\ "
+		}
+		return "\n"
+	default:
+		return r.inlines(node.Children)
+	}
+}
+
+// escape keeps plain text from being read as markup: brackets and braces
+// always, and marks only where Jira would take them as the start of one.
+func (r *jiraRenderer) escape(text string) string {
+	runes := []rune(text)
+	var b strings.Builder
+	for i, c := range runes {
+		switch {
+		case c == '[' || c == '{' || c == '\\':
+			b.WriteRune('\\')
+		case c == '|' && r.inTable:
+			b.WriteRune('\\')
+		case strings.ContainsRune("*_-+^~!", c):
+			opens := (i == 0 || !isWordRune(runes[i-1])) &&
+				i+1 < len(runes) && !unicode.IsSpace(runes[i+1])
+			if opens {
+				b.WriteRune('\\')
+			}
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
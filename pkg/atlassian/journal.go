@@ -0,0 +1,151 @@
+package atlassian
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// JournalTransition is a journal entry for a status change
+	JournalTransition = "transition"
+	// JournalAssign is a journal entry for an assignee change
+	JournalAssign = "assign"
+)
+
+// JournalEntry records one change jt made to an issue,
+// with everything needed to undo it.
+type JournalEntry struct {
+	Time     time.Time `json:"time"`
+	IssueKey string    `json:"issueKey"`
+	Action   string    `json:"action"`
+	// FromStatus and ToStatus are status names, for transitions
+	FromStatus string `json:"fromStatus,omitempty"`
+	ToStatus   string `json:"toStatus,omitempty"`
+	Transition string `json:"transition,omitempty"`
+	// FromAssignee and ToAssignee are account IDs, empty meaning unassigned
+	FromAssignee string `json:"fromAssignee,omitempty"`
+	ToAssignee   string `json:"toAssignee,omitempty"`
+}
+
+// String describes the change for people
+func (e JournalEntry) String() string {
+	when := e.Time.Local().Format("2006-01-02 15:04")
+	if e.Action == JournalAssign {
+		return fmt.Sprintf("%s %s assigned to %s (was %s)",
+			when, e.IssueKey, accountOrNobody(e.ToAssignee), accountOrNobody(e.FromAssignee))
+	}
+	return fmt.Sprintf("%s %s moved from %s to %s", when, e.IssueKey, e.FromStatus, e.ToStatus)
+}
+
+func accountOrNobody(accountID string) string {
+	if accountID == "" {
+		return "nobody"
+	}
+	return accountID
+}
+
+// Journal is an append-only log of the changes jt has made, one JSON
+// entry per line. A nil Journal records nothing.
+type Journal struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewJournal returns a journal kept in the file at path
+func NewJournal(path string) *Journal {
+	return &Journal{path: path}
+}
+
+// Record appends an entry to the journal, stamping it with the current time
+func (j *Journal) Record(entry JournalEntry) error {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(j.path), 0o771); err != nil {
+		return err
+	}
+	w, err := os.OpenFile(j.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	_, err = w.Write(append(line, '\n'))
+	return err
+}
+
+// Entries returns everything in the journal, oldest first
+func (j *Journal) Entries() ([]JournalEntry, error) {
+	if j == nil {
+		return nil, nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.read()
+}
+
+func (j *Journal) read() ([]JournalEntry, error) {
+	f, err := os.Open(j.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("unable to read journal %s: %w", j.path, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// DropLast removes the newest n entries from the journal
+func (j *Journal) DropLast(n int) error {
+	if j == nil || n <= 0 {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entries, err := j.read()
+	if err != nil {
+		return err
+	}
+	if n > len(entries) {
+		n = len(entries)
+	}
+	var kept []byte
+	for _, entry := range entries[:len(entries)-n] {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		kept = append(append(kept, line...), '\n')
+	}
+	return ioutil.WriteFile(j.path, kept, 0o600)
+}
@@ -0,0 +1,95 @@
+package atlassian
+
+import "strings"
+
+// jiraLanguages maps the languages Jira's {code} macro knows to the info
+// strings Github uses for fenced code. An empty string means plain text.
+var jiraLanguages = map[string]string{
+	"actionscript": "actionscript",
+	"ada":          "ada",
+	"applescript":  "applescript",
+	"bash":         "bash",
+	"c":            "c",
+	"c#":           "csharp",
+	"c++":          "cpp",
+	"cpp":          "cpp",
+	"css":          "css",
+	"erlang":       "erlang",
+	"go":           "go",
+	"groovy":       "groovy",
+	"haskell":      "haskell",
+	"html":         "html",
+	"java":         "java",
+	"javascript":   "javascript",
+	"js":           "javascript",
+	"json":         "json",
+	"lua":          "lua",
+	"none":         "",
+	"nyan":         "",
+	"objc":         "objectivec",
+	"perl":         "perl",
+	"php":          "php",
+	"python":       "python",
+	"r":            "r",
+	"rainbow":      "",
+	"ruby":         "ruby",
+	"scala":        "scala",
+	"sh":           "sh",
+	"sql":          "sql",
+	"swift":        "swift",
+	"visualbasic":  "vbnet",
+	"xml":          "xml",
+	"yaml":         "yaml",
+}
+
+// markdownAliases maps other common Github info strings to the Jira
+// language closest to them, for languages Jira spells differently.
+var markdownAliases = map[string]string{
+	"csharp":      "c#",
+	"cs":          "c#",
+	"objectivec":  "objc",
+	"objective-c": "objc",
+	"vbnet":       "visualbasic",
+	"vb":          "visualbasic",
+	"shell":       "bash",
+	"zsh":         "bash",
+	"console":     "bash",
+	"golang":      "go",
+	"py":          "python",
+	"rb":          "ruby",
+	"ts":          "javascript",
+	"typescript":  "javascript",
+	"jsx":         "javascript",
+	"yml":         "yaml",
+	"htm":         "html",
+	"svg":         "xml",
+}
+
+// MarkdownLanguage returns the Github info string for a Jira code language.
+// Languages Jira doesn't know are passed through, in lower case.
+func MarkdownLanguage(jiraLanguage string) string {
+	lang := strings.ToLower(strings.TrimSpace(jiraLanguage))
+	if md, ok := jiraLanguages[lang]; ok {
+		return md
+	}
+	return lang
+}
+
+// JiraLanguage returns the Jira code language for a Github info string,
+// or "" if Jira has no formatter for it, since Jira shows an error in
+// place of code in a language it doesn't know.
+func JiraLanguage(markdownLanguage string) string {
+	info := strings.Fields(strings.ToLower(markdownLanguage))
+	if len(info) == 0 {
+		return ""
+	}
+	// anything after the language in an info string is for other tools
+	lang := info[0]
+	if jira, ok := markdownAliases[lang]; ok {
+		return jira
+	}
+	if md, ok := jiraLanguages[lang]; ok && md != "" {
+		return lang
+	}
+	return ""
+}
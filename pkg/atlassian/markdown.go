@@ -0,0 +1,310 @@
+package atlassian
+
+import (
+	"html"
+	"strings"
+)
+
+// MarkdownOptions change how a document is rendered as Github Markdown
+type MarkdownOptions struct {
+	// Mention shows a mentioned user, given their account ID.
+	// Without it, mentions are shown as @accountID.
+	Mention func(accountID string) string
+	// Image gives the URL to show an image from, given its source in the
+	// markup, which is often just the file name of an attachment.
+	// Without it, sources are used as they are.
+	Image func(source string) string
+	// Issues links issue keys in text to the issues, and makes links
+	// relative to Jira absolute. Without it, keys stay as text.
+	Issues *IssueLinker
+}
+
+// JiraToMD translates Jira wiki markup to Github Markdown, by parsing it
+// into a document and rendering that.
+func JiraToMD(str string) string {
+	return RenderMarkdown(ParseJira(str), MarkdownOptions{})
+}
+
+// RenderMarkdown renders a document as Github Markdown
+func RenderMarkdown(doc *Node, opts MarkdownOptions) string {
+	r := &mdRenderer{opts: opts}
+	return r.blocks(doc.Children)
+}
+
+type mdRenderer struct {
+	opts MarkdownOptions
+	// inTable is set while rendering table cells, which must stay on one line
+	inTable bool
+	// inLink is set while rendering link text, where keys can't be links
+	inLink bool
+}
+
+// blocks renders blocks separated by blank lines
+func (r *mdRenderer) blocks(nodes []*Node) string {
+	var out []string
+	for _, node := range nodes {
+		out = append(out, r.block(node))
+	}
+	return strings.Join(out, "\n\n")
+}
+
+func (r *mdRenderer) block(node *Node) string {
+	switch node.Kind {
+	case HeadingNode:
+		return strings.Repeat("#", node.Level) + " " + r.inlines(node.Children)
+	case RuleNode:
+		return "---"
+	case CodeBlockNode:
+		fence := codeFence(node.Text, 3)
+		return fence + node.Language + "\n" + node.Text + "\n" + fence
+	case QuoteNode:
+		return prefixLines(r.blocks(node.Children), "> ")
+	case PanelNode:
+		return r.panel(node)
+	case ListNode:
+		return r.list(node, "")
+	case TableNode:
+		return r.table(node)
+	case ParagraphNode:
+		return r.inlines(node.Children)
+	default:
+		return r.inline(node)
+	}
+}
+
+// alerts maps Jira's panel macros to the Github alerts that look most
+// like them: {note} is yellow and {warning} red in Jira, like Github's
+// WARNING and CAUTION.
+var alerts = map[string]string{
+	"info":    "NOTE",
+	"panel":   "NOTE",
+	"tip":     "TIP",
+	"note":    "WARNING",
+	"warning": "CAUTION",
+}
+
+// panel renders a panel as a Github alert. Alerts have no titles of their
+// own, so a panel's title is shown in bold at the top.
+func (r *mdRenderer) panel(node *Node) string {
+	alert, ok := alerts[node.Macro]
+	if !ok {
+		alert = "NOTE"
+	}
+	body := r.blocks(node.Children)
+	if title := node.Params["title"]; title != "" {
+		body = "**" + title + "**\n\n" + body
+	}
+	return prefixLines("[!"+alert+"]\n"+body, "> ")
+}
+
+// list renders a list, indenting each nested list under its item's text
+func (r *mdRenderer) list(node *Node, indent string) string {
+	marker := "* "
+	if node.Ordered {
+		marker = "1. "
+	}
+	hanging := indent + strings.Repeat(" ", len(marker))
+	var lines []string
+	for _, item := range node.Children {
+		started := false
+		for _, child := range item.Children {
+			switch {
+			case child.Kind == ListNode:
+				if !started {
+					// an item that only holds a nested list
+					lines = append(lines, indent+strings.TrimSpace(marker))
+				}
+				lines = append(lines, r.list(child, hanging))
+			case !started:
+				box := ""
+				if item.Task {
+					box = "[ ] "
+					if item.Checked {
+						box = "[x] "
+					}
+				}
+				lines = append(lines, indent+marker+box+prefixFollowingLines(r.block(child), hanging))
+			default:
+				lines = append(lines, prefixLines(r.block(child), hanging))
+			}
+			started = true
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// table renders a table, using the first row as the header since
+// Github Markdown tables must have one.
+func (r *mdRenderer) table(node *Node) string {
+	r.inTable = true
+	defer func() { r.inTable = false }()
+
+	// a table needs a column, even an empty one, to be a table
+	columns := 1
+	for _, row := range node.Children {
+		if len(row.Children) > columns {
+			columns = len(row.Children)
+		}
+	}
+	var lines []string
+	for i, row := range node.Children {
+		cells := make([]string, columns)
+		for c, cell := range row.Children {
+			cells[c] = r.inlines(cell.Children)
+		}
+		lines = append(lines, "| "+strings.Join(cells, " | ")+" |")
+		if i == 0 {
+			lines = append(lines, "|"+strings.Repeat(" --- |", columns))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (r *mdRenderer) inlines(nodes []*Node) string {
+	var b strings.Builder
+	for _, node := range nodes {
+		b.WriteString(r.inline(node))
+	}
+	return b.String()
+}
+
+func (r *mdRenderer) inline(node *Node) string {
+	switch node.Kind {
+	case TextNode:
+		text := node.Text
+		if node.Escaped {
+			if strings.ContainsAny(text, "\\`*_{}[]()#+-.!|~<>") {
+				text = "\\" + text
+			}
+			return text
+		}
+		if r.inTable {
+			text = strings.ReplaceAll(text, "|", "\\|")
+		}
+		if r.opts.Issues != nil && !r.inLink {
+			text = r.opts.Issues.linkKeys(text, r.inTable)
+		}
+		return text
+	case StrongNode:
+		return "**" + r.inlines(node.Children) + "**"
+	case EmphasisNode:
+		return "*" + r.inlines(node.Children) + "*"
+	case StrikeNode:
+		return "~~" + r.inlines(node.Children) + "~~"
+	case InsertNode:
+		return "<ins>" + r.inlines(node.Children) + "</ins>"
+	case SuperscriptNode:
+		return "<sup>" + r.inlines(node.Children) + "</sup>"
+	case SubscriptNode:
+		return "<sub>" + r.inlines(node.Children) + "</sub>"
+	case CitationNode:
+		return "<cite>" + r.inlines(node.Children) + "</cite>"
+	case MonospaceNode:
+		text := node.Text
+		if r.inTable {
+			// Github splits table cells on bars, even inside code
+			text = strings.ReplaceAll(text, "|", "\\|")
+		}
+		fence := codeFence(text, 1)
+		if strings.Contains(text, "`") {
+			return fence + " " + text + " " + fence
+		}
+		return fence + text + fence
+	case LinkNode:
+		return r.link(node)
+	case ImageNode:
+		src := node.URL
+		if r.opts.Image != nil {
+			src = r.opts.Image(src)
+		}
+		if r.opts.Issues != nil {
+			src = r.opts.Issues.Resolve(src)
+		}
+		return markdownImage(src, node.Params)
+	case MentionNode:
+		if r.opts.Mention != nil {
+			return r.opts.Mention(node.Text)
+		}
+		return "@" + node.Text
+	case LineBreakNode:
+		if r.inTable {
+			return "<br>"
+		}
+		return "\n"
+	default:
+		return r.inlines(node.Children)
+	}
+}
+
+// link renders a link, like [TEAM-12] to an issue or [text|/path] on the Jira site
+func (r *mdRenderer) link(node *Node) string {
+	url := node.URL
+	if issues := r.opts.Issues; issues != nil {
+		if len(node.Children) == 0 && issues.IsKey(url) {
+			return issues.link(url, r.inTable)
+		}
+		url = issues.Resolve(url)
+	}
+	if len(node.Children) == 0 {
+		return "<" + url + ">"
+	}
+	r.inLink = true
+	text := r.inlines(node.Children)
+	r.inLink = false
+	return "[" + text + "](" + url + ")"
+}
+
+// markdownImage shows an image, as an HTML <img> when it has a size,
+// since Markdown has no way to give one.
+func markdownImage(src string, params map[string]string) string {
+	src = strings.ReplaceAll(src, " ", "%20")
+	if params["width"] == "" && params["height"] == "" {
+		return "![" + params["alt"] + "](" + src + ")"
+	}
+	var b strings.Builder
+	b.WriteString(`<img src="` + html.EscapeString(src) + `"`)
+	for _, attr := range []string{"width", "height", "alt"} {
+		if value := params[attr]; value != "" {
+			b.WriteString(" " + attr + `="` + html.EscapeString(value) + `"`)
+		}
+	}
+	b.WriteString(">")
+	return b.String()
+}
+
+// codeFence returns a run of backticks longer than any in text
+func codeFence(text string, min int) string {
+	longest, run := 0, 0
+	for _, r := range text {
+		if r == '`' {
+			run++
+			if run > longest {
+				longest = run
+			}
+		} else {
+			run = 0
+		}
+	}
+	if longest+1 > min {
+		min = longest + 1
+	}
+	return strings.Repeat("`", min)
+}
+
+// prefixLines puts prefix in front of every line of text
+func prefixLines(text, prefix string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(prefix+line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// prefixFollowingLines puts prefix in front of every line of text but the first
+func prefixFollowingLines(text, prefix string) string {
+	lines := strings.Split(text, "\n")
+	for i := 1; i < len(lines); i++ {
+		lines[i] = prefix + lines[i]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,44 @@
+package atlassian
+
+import "testing"
+
+func TestJiraToMD(t *testing.T) {
+	tests := []struct {
+		name, jira, want string
+	}{
+		{"heading", "h1. Title", "# Title"},
+		{"heading with marks", "h3. Sub *bold*", "### Sub **bold**"},
+		{"bold and italic", "*bold* and _italic_", "**bold** and *italic*"},
+		{"monospace", "{{a*b*c}}", "`a*b*c`"},
+		{"citation", "??Some Book??", "<cite>Some Book</cite>"},
+		{"inserted", "+inserted+", "<ins>inserted</ins>"},
+		{"superscript", "^sup^", "<sup>sup</sup>"},
+		{"subscript", "~sub~", "<sub>sub</sub>"},
+		{"strikethrough", "-gone- but well-known", "~~gone~~ but well-known"},
+		{"code", "{code:java}\nint x = 1;\n{code}", "```java\nint x = 1;\n```"},
+		{"code without a language", "{code}\nplain\n{code}", "```\nplain\n```"},
+		{"noformat", "{noformat}\n*not bold*\n{noformat}", "```\n*not bold*\n```"},
+		{"link", "[Example|http://example.com]", "[Example](http://example.com)"},
+		{"bare link", "[http://example.com]", "<http://example.com>"},
+		{"url in text", "see http://example.com/*x*.", "see <http://example.com/*x*>."},
+		{"image", "!cat.png!", "![](cat.png)"},
+		{"image with size", "!cat.png|width=300,alt=A cat!", `<img src="cat.png" width="300" alt="A cat">`},
+		{"quote", "bq. quoted", "> quoted"},
+		{"quote macro", "{quote}\nq\n{quote}", "> q"},
+		{"color", "{color:red}red{color} text", "red text"},
+		{"panel", "{panel:title=Heads up}\ninside\n{panel}", "> [!NOTE]\n> **Heads up**\n>\n> inside"},
+		{"table", "||a||b||\n|1|2|", "| a | b |\n| --- | --- |\n| 1 | 2 |"},
+		{"empty table", "||", "|  |\n| --- |"},
+		{"nested lists", "# one\n#* sub\n#* sub2\n# two", "1. one\n   * sub\n   * sub2\n1. two"},
+		{"deep list", "* a\n** b\n*** c", "* a\n  * b\n    * c"},
+		{"rule", "----", "---"},
+		{"line break", `line\\break`, "line\nbreak"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := JiraToMD(tt.jira); got != tt.want {
+				t.Errorf("JiraToMD(%q) = %q, want %q", tt.jira, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,453 @@
+package atlassian
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// NodeKind is the kind of element a Node is in a markup document
+type NodeKind int
+
+const (
+	// DocumentNode is the root of every document
+	DocumentNode NodeKind = iota
+	ParagraphNode
+	HeadingNode
+	ListNode
+	ListItemNode
+	CodeBlockNode
+	QuoteNode
+	PanelNode
+	TableNode
+	TableRowNode
+	TableCellNode
+	RuleNode
+
+	TextNode
+	StrongNode
+	EmphasisNode
+	StrikeNode
+	InsertNode
+	SuperscriptNode
+	SubscriptNode
+	CitationNode
+	MonospaceNode
+	LinkNode
+	ImageNode
+	MentionNode
+	LineBreakNode
+)
+
+// Node is an element of a markup document. Which fields mean anything
+// depends on its Kind.
+type Node struct {
+	Kind     NodeKind
+	Children []*Node
+	// Text is the content of text, monospace and code block nodes,
+	// and the account ID of a mention
+	Text string
+	// Escaped text was written with a backslash, so it is never markup
+	Escaped bool
+	// Level is a heading's level, from 1 to 6
+	Level int
+	// Ordered is set on numbered lists
+	Ordered bool
+	// Header is set on table header cells
+	Header bool
+	// Task is set on list items that are checkboxes, and Checked on those that are done
+	Task    bool
+	Checked bool
+	// Macro is the macro a panel or code block came from, like "info" or "noformat"
+	Macro string
+	// Params are a macro's or an image's parameters, like title=Notes
+	Params map[string]string
+	// Language is a code block's language, as a Github info string like "csharp"
+	Language string
+	// URL is where a link goes, or where an image comes from
+	URL string
+}
+
+// ParseJira parses Jira wiki markup into a document
+func ParseJira(markup string) *Node {
+	p := &blockParser{tokens: tokenizeMarkup(markup)}
+	return &Node{Kind: DocumentNode, Children: p.blocks("")}
+}
+
+// lineKind is what a line of Jira markup turned out to be
+type lineKind int
+
+const (
+	textLine lineKind = iota
+	blankLine
+	headingLine
+	listLine
+	quoteLine
+	tableLine
+	ruleLine
+	// macroTag opens or closes a macro that holds other blocks, like {panel}
+	macroTag
+	// codeStart opens a macro whose contents are not markup, like {code}
+	codeStart
+	codeEnd
+	// rawLine is a line inside a code macro
+	rawLine
+)
+
+type token struct {
+	kind lineKind
+	text string
+	// level is a heading's level
+	level int
+	// markers are a list item's bullets, like "*#"
+	markers string
+	macro   string
+	params  map[string]string
+	// bare macro tags have no parameters, so they might be closing tags
+	bare bool
+}
+
+var (
+	blockMacroRe = regexp.MustCompile(
+		`\{(code|noformat|panel|quote|info|note|warning|tip)(?::([^}]*))?\}`)
+	headingLineRe = regexp.MustCompile(`^\s*h([1-6])\.\s*(.*)$`)
+	listLineRe    = regexp.MustCompile(`^\s*([*#]+|-)\s+(.*)$`)
+	quoteLineRe   = regexp.MustCompile(`^\s*bq\.\s*(.*)$`)
+	ruleLineRe    = regexp.MustCompile(`^\s*-{4,}\s*$`)
+)
+
+// tokenizeMarkup splits markup into lines, splitting out block macro tags so
+// they stand alone, and classifies each one.
+func tokenizeMarkup(markup string) []token {
+	markup = strings.ReplaceAll(markup, "\r\n", "\n")
+	var tokens []token
+	// raw is the code macro we're inside, whose lines are copied as they are
+	raw := ""
+	for _, line := range strings.Split(markup, "\n") {
+		for {
+			if raw != "" {
+				end := strings.Index(line, "{"+raw+"}")
+				if end < 0 {
+					tokens = append(tokens, token{kind: rawLine, text: line})
+					break
+				}
+				if before := line[:end]; strings.TrimSpace(before) != "" {
+					tokens = append(tokens, token{kind: rawLine, text: before})
+				}
+				tokens = append(tokens, token{kind: codeEnd, macro: raw})
+				line = line[end+len(raw)+2:]
+				raw = ""
+			} else {
+				loc := findBlockMacro(line)
+				if loc == nil {
+					tokens = append(tokens, classifyLine(line))
+					break
+				}
+				if before := line[:loc[0]]; strings.TrimSpace(before) != "" {
+					tokens = append(tokens, classifyLine(before))
+				}
+				tok := token{kind: macroTag, macro: line[loc[2]:loc[3]], bare: loc[4] < 0}
+				if !tok.bare {
+					tok.params = parseMacroParams(line[loc[4]:loc[5]])
+				}
+				if tok.macro == "code" || tok.macro == "noformat" {
+					tok.kind = codeStart
+					raw = tok.macro
+				}
+				tokens = append(tokens, tok)
+				line = line[loc[1]:]
+			}
+			if strings.TrimSpace(line) == "" {
+				break
+			}
+		}
+	}
+	return tokens
+}
+
+// findBlockMacro finds the first block macro tag in a line, skipping
+// monospaced text like {{code}} that only looks like one.
+func findBlockMacro(line string) []int {
+	for _, loc := range blockMacroRe.FindAllStringSubmatchIndex(line, -1) {
+		if loc[0] > 0 && line[loc[0]-1] == '{' {
+			continue
+		}
+		if inMonospace(line, loc[0]) {
+			continue
+		}
+		if loc[1] < len(line) && line[loc[1]] == '}' {
+			continue
+		}
+		return loc
+	}
+	return nil
+}
+
+// inMonospace reports whether i falls inside a {{monospace}} span of line
+func inMonospace(line string, i int) bool {
+	for start := 0; ; {
+		open := strings.Index(line[start:], "{{")
+		if open < 0 || start+open > i {
+			return false
+		}
+		open += start
+		end := strings.Index(line[open+2:], "}}")
+		if end < 0 {
+			return false
+		}
+		end += open + 2
+		if i < end {
+			return true
+		}
+		start = end + 2
+	}
+}
+
+func classifyLine(line string) token {
+	switch {
+	case strings.TrimSpace(line) == "":
+		return token{kind: blankLine}
+	case ruleLineRe.MatchString(line):
+		return token{kind: ruleLine}
+	}
+	if g := headingLineRe.FindStringSubmatch(line); g != nil {
+		level, _ := strconv.Atoi(g[1])
+		return token{kind: headingLine, level: level, text: g[2]}
+	}
+	if g := listLineRe.FindStringSubmatch(line); g != nil {
+		return token{kind: listLine, markers: g[1], text: g[2]}
+	}
+	if g := quoteLineRe.FindStringSubmatch(line); g != nil {
+		return token{kind: quoteLine, text: g[1]}
+	}
+	if strings.HasPrefix(strings.TrimSpace(line), "|") {
+		return token{kind: tableLine, text: strings.TrimSpace(line)}
+	}
+	return token{kind: textLine, text: line}
+}
+
+// parseMacroParams parses macro parameters like "java" or
+// "title=Notes|borderStyle=solid". A leading parameter without a name,
+// like a code block's language, is stored under "".
+func parseMacroParams(params string) map[string]string {
+	parsed := make(map[string]string)
+	for i, param := range strings.Split(params, "|") {
+		param = strings.TrimSpace(param)
+		if param == "" {
+			continue
+		}
+		if eq := strings.Index(param, "="); eq >= 0 {
+			parsed[strings.TrimSpace(param[:eq])] = strings.TrimSpace(param[eq+1:])
+		} else if i == 0 {
+			parsed[""] = param
+		} else {
+			parsed[param] = ""
+		}
+	}
+	return parsed
+}
+
+type blockParser struct {
+	tokens []token
+	pos    int
+}
+
+// blocks parses blocks until the end of the document, or until the
+// closing tag of the container macro we're in.
+func (p *blockParser) blocks(container string) []*Node {
+	var nodes []*Node
+	for p.pos < len(p.tokens) {
+		tok := p.tokens[p.pos]
+		switch tok.kind {
+		case blankLine, codeEnd, rawLine:
+			p.pos++
+		case macroTag:
+			p.pos++
+			if tok.macro == container && tok.bare {
+				return nodes
+			}
+			node := &Node{Kind: PanelNode, Macro: tok.macro, Params: tok.params}
+			if tok.macro == "quote" {
+				node.Kind = QuoteNode
+			}
+			node.Children = p.blocks(tok.macro)
+			nodes = append(nodes, node)
+		case codeStart:
+			nodes = append(nodes, p.codeBlock())
+		case headingLine:
+			p.pos++
+			nodes = append(nodes, &Node{
+				Kind:     HeadingNode,
+				Level:    tok.level,
+				Children: parseInline(strings.TrimSpace(tok.text)),
+			})
+		case ruleLine:
+			p.pos++
+			nodes = append(nodes, &Node{Kind: RuleNode})
+		case quoteLine:
+			p.pos++
+			nodes = append(nodes, &Node{Kind: QuoteNode, Children: []*Node{{
+				Kind:     ParagraphNode,
+				Children: parseInline(strings.TrimSpace(tok.text)),
+			}}})
+		case listLine:
+			nodes = append(nodes, p.list())
+		case tableLine:
+			nodes = append(nodes, p.table())
+		default:
+			nodes = append(nodes, p.paragraph())
+		}
+	}
+	return nodes
+}
+
+func (p *blockParser) codeBlock() *Node {
+	tok := p.tokens[p.pos]
+	p.pos++
+	node := &Node{Kind: CodeBlockNode, Macro: tok.macro, Params: tok.params}
+	if tok.macro == "code" {
+		lang := tok.params[""]
+		if named, ok := tok.params["language"]; ok {
+			lang = named
+		}
+		node.Language = MarkdownLanguage(lang)
+	}
+	var lines []string
+	for ; p.pos < len(p.tokens); p.pos++ {
+		if p.tokens[p.pos].kind == codeEnd {
+			p.pos++
+			break
+		}
+		lines = append(lines, p.tokens[p.pos].text)
+	}
+	node.Text = strings.Join(lines, "\n")
+	return node
+}
+
+// paragraph gathers consecutive lines of text, which Jira shows with
+// their line breaks.
+func (p *blockParser) paragraph() *Node {
+	var lines []string
+	for ; p.pos < len(p.tokens) && p.tokens[p.pos].kind == textLine; p.pos++ {
+		lines = append(lines, strings.TrimRight(p.tokens[p.pos].text, " \t"))
+	}
+	return &Node{Kind: ParagraphNode, Children: parseInline(strings.Join(lines, "\n"))}
+}
+
+// list gathers consecutive list items, nesting them by the number of
+// bullets in front of them. A top level item of the other kind of list
+// starts a new list.
+func (p *blockParser) list() *Node {
+	first := p.tokens[p.pos]
+	root := &Node{Kind: ListNode, Ordered: first.markers[0] == '#'}
+	stack := []*Node{root}
+	for ; p.pos < len(p.tokens) && p.tokens[p.pos].kind == listLine; p.pos++ {
+		tok := p.tokens[p.pos]
+		depth := len(tok.markers)
+		if depth == 1 && (tok.markers[0] == '#') != root.Ordered {
+			break
+		}
+		if len(stack) > depth {
+			stack = stack[:depth]
+		}
+		for len(stack) < depth {
+			parent := stack[len(stack)-1]
+			if len(parent.Children) == 0 {
+				parent.Children = append(parent.Children, &Node{Kind: ListItemNode})
+			}
+			item := parent.Children[len(parent.Children)-1]
+			nested := &Node{Kind: ListNode, Ordered: tok.markers[len(stack)] == '#'}
+			item.Children = append(item.Children, nested)
+			stack = append(stack, nested)
+		}
+		text := strings.TrimSpace(tok.text)
+		item := &Node{Kind: ListItemNode}
+		for box, checked := range map[string]bool{taskTodo: false, taskDone: true} {
+			if strings.HasPrefix(text, box+" ") {
+				item.Task, item.Checked = true, checked
+				text = strings.TrimSpace(strings.TrimPrefix(text, box))
+			}
+		}
+		item.Children = []*Node{{Kind: ParagraphNode, Children: parseInline(text)}}
+		top := stack[len(stack)-1]
+		top.Children = append(top.Children, item)
+	}
+	return root
+}
+
+func (p *blockParser) table() *Node {
+	table := &Node{Kind: TableNode}
+	for ; p.pos < len(p.tokens) && p.tokens[p.pos].kind == tableLine; p.pos++ {
+		table.Children = append(table.Children,
+			&Node{Kind: TableRowNode, Children: splitCells(p.tokens[p.pos].text)})
+	}
+	return table
+}
+
+// splitCells splits a table row like "||Name||Size||" or "|a|[b|http://b]|"
+// into cells, leaving bars inside links, images and monospace alone.
+func splitCells(row string) []*Node {
+	var cells []*Node
+	var cell strings.Builder
+	started, header := false, false
+	flush := func() {
+		if started {
+			cells = append(cells, &Node{
+				Kind:     TableCellNode,
+				Header:   header,
+				Children: parseInline(strings.TrimSpace(cell.String())),
+			})
+		}
+		cell.Reset()
+	}
+	// skip copies an opaque span from i up to and including its closing delimiter
+	skip := func(i int, open, close string) int {
+		end := strings.Index(row[i+len(open):], close)
+		if end < 0 {
+			cell.WriteString(open)
+			return i + len(open)
+		}
+		end += i + len(open) + len(close)
+		cell.WriteString(row[i:end])
+		return end
+	}
+	for i := 0; i < len(row); {
+		switch {
+		case row[i] == '\\' && i+1 < len(row):
+			cell.WriteString(row[i : i+2])
+			i += 2
+		case row[i] == '|':
+			flush()
+			started = true
+			header = strings.HasPrefix(row[i:], "||")
+			if header {
+				i += 2
+			} else {
+				i++
+			}
+		case strings.HasPrefix(row[i:], "{{"):
+			i = skip(i, "{{", "}}")
+		case row[i] == '[':
+			i = skip(i, "[", "]")
+		case row[i] == '!' && isImageAt(row, i):
+			i = skip(i, "!", "!")
+		default:
+			cell.WriteByte(row[i])
+			i++
+		}
+	}
+	if strings.TrimSpace(cell.String()) != "" {
+		flush()
+	}
+	return cells
+}
+
+// isImageAt reports whether the ! at i starts an image like !shot.png|thumbnail!
+func isImageAt(s string, i int) bool {
+	end := strings.IndexByte(s[i+1:], '!')
+	if end <= 0 {
+		return false
+	}
+	inner := s[i+1 : i+1+end]
+	return strings.TrimSpace(inner) == inner && !strings.Contains(inner, "\n")
+}
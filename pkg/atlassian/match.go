@@ -0,0 +1,177 @@
+package atlassian
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+const (
+	// minMatchScore is the lowest score we will consider a plausible match
+	minMatchScore = 0.5
+	// clearWinMargin is how far ahead of the runner-up the best match must be
+	clearWinMargin = 0.15
+	// didYouMeanCount is how many candidates we list when we can't decide
+	didYouMeanCount = 3
+)
+
+// ScoredTransition is a transition along with how well its target status
+// matched what the user asked for, from 0 (nothing alike) to 1 (exact).
+type ScoredTransition struct {
+	jira.Transition
+	Score float64
+}
+
+// RankTransitions scores every possible transition against statusName, best first.
+func RankTransitions(possibleTransitions []jira.Transition, statusName string) []ScoredTransition {
+	ranked := make([]ScoredTransition, len(possibleTransitions))
+	for i, v := range possibleTransitions {
+		ranked[i] = ScoredTransition{Transition: v, Score: scoreStatus(v.To.Name, statusName)}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Score > ranked[j].Score
+	})
+	return ranked
+}
+
+// MatchTransitions returns the plausible matches for statusName, best first.
+// A single result means it clearly beat every other transition.
+// Several results mean the user needs to decide between them.
+func MatchTransitions(possibleTransitions []jira.Transition, statusName string) []ScoredTransition {
+	ranked := RankTransitions(possibleTransitions, statusName)
+	var matches []ScoredTransition
+	for _, v := range ranked {
+		if v.Score >= minMatchScore {
+			matches = append(matches, v)
+		}
+	}
+	if len(matches) > 1 && matches[0].Score-matches[1].Score >= clearWinMargin {
+		return matches[:1]
+	}
+	return matches
+}
+
+// DidYouMean lists the top ranked candidates with their scores, to suggest
+// when there is no clear match
+func DidYouMean(ranked []ScoredTransition) string {
+	if len(ranked) > didYouMeanCount {
+		ranked = ranked[:didYouMeanCount]
+	}
+	names := make([]string, len(ranked))
+	for i, v := range ranked {
+		names[i] = fmt.Sprintf("%q (%.2f)", v.To.Name, v.Score)
+	}
+	return strings.Join(names, ", ")
+}
+
+// scoreStatus rates how well input matches a status name.
+// Exact (case-insensitive) matches score 1, matches that only differ by
+// whitespace (so "ToDo" will match "TO DO") score 0.95, and anything else
+// is a blend of edit distance, token overlap (so "Done" will still match
+// "Deployed / Done") and a bonus for typing the start of the name.
+func scoreStatus(status, input string) float64 {
+	status, input = strings.ToLower(strings.TrimSpace(status)), strings.ToLower(strings.TrimSpace(input))
+	if status == "" || input == "" {
+		return 0
+	}
+	if status == input {
+		return 1
+	}
+	squashedStatus, squashedInput := removeWhiteSpace(status), removeWhiteSpace(input)
+	if squashedStatus == squashedInput {
+		return 0.95
+	}
+
+	score := 0.4*similarity(squashedStatus, squashedInput) +
+		0.5*tokenOverlap(tokenize(status), tokenize(input))
+	if strings.HasPrefix(squashedStatus, squashedInput) {
+		score += 0.1
+	}
+	// only exact and whitespace-insensitive matches get to be near certain
+	if score > 0.9 {
+		score = 0.9
+	}
+	return score
+}
+
+// tokenOverlap is the average, over the input tokens, of how well each
+// one matches its closest status token. Typing the start of a word
+// counts as a good match, so "prog" is close to "In Progress".
+func tokenOverlap(statusTokens, inputTokens []string) float64 {
+	if len(statusTokens) == 0 || len(inputTokens) == 0 {
+		return 0
+	}
+	var total float64
+	for _, in := range inputTokens {
+		var best float64
+		for _, st := range statusTokens {
+			s := similarity(st, in)
+			if len(in) > 1 && strings.HasPrefix(st, in) && s < 0.9 {
+				s = 0.9
+			}
+			if s > best {
+				best = s
+			}
+		}
+		total += best
+	}
+	return total / float64(len(inputTokens))
+}
+
+func tokenize(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// similarity turns edit distance into a 0-1 score
+func similarity(a, b string) float64 {
+	longest := len([]rune(a))
+	if l := len([]rune(b)); l > longest {
+		longest = l
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(editDistance(a, b))/float64(longest)
+}
+
+// editDistance is the optimal string alignment distance, which is
+// Levenshtein plus transpositions, so "reveiw" is only one edit from "review".
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = minInt(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
+
+func minInt(first int, rest ...int) int {
+	m := first
+	for _, v := range rest {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
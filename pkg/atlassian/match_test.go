@@ -0,0 +1,129 @@
+package atlassian
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+func testTransitions(statuses ...string) []jira.Transition {
+	transitions := make([]jira.Transition, len(statuses))
+	for i, s := range statuses {
+		transitions[i] = jira.Transition{ID: s, Name: s, To: jira.Status{Name: s}}
+	}
+	return transitions
+}
+
+func TestMatchTransitions(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []string
+		input    string
+		want     []string
+	}{
+		{
+			name:     "typo",
+			statuses: []string{"To Do", "In Progress", "Done"},
+			input:    "in progres",
+			want:     []string{"In Progress"},
+		},
+		{
+			name:     "missing space",
+			statuses: []string{"To Do", "In Progress", "Done"},
+			input:    "todo",
+			want:     []string{"To Do"},
+		},
+		{
+			name:     "exact match wins",
+			statuses: []string{"Review", "In Review", "Code Review"},
+			input:    "review",
+			want:     []string{"Review"},
+		},
+		{
+			name:     "typo with a similar status",
+			statuses: []string{"To Do", "In Review", "Code Review", "Done"},
+			input:    "in reveiw",
+			want:     []string{"In Review"},
+		},
+		{
+			name:     "ambiguous",
+			statuses: []string{"To Do", "In Review", "Code Review", "Done"},
+			input:    "reveiw",
+			want:     []string{"In Review", "Code Review"},
+		},
+		{
+			name:     "nothing close",
+			statuses: []string{"To Do", "In Progress", "Done"},
+			input:    "xyzzy",
+			want:     nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, m := range MatchTransitions(testTransitions(tt.statuses...), tt.input) {
+				got = append(got, m.To.Name)
+			}
+			if len(got) > 1 {
+				// ties may come in either order
+				if !sameNames(got, tt.want) {
+					t.Errorf("MatchTransitions(%q) = %q, want %q", tt.input, got, tt.want)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MatchTransitions(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDidYouMean(t *testing.T) {
+	ranked := RankTransitions(testTransitions("To Do", "In Review", "Code Review", "Done", "Closed"), "reveiw")
+	got := DidYouMean(ranked)
+	for _, want := range []string{`"In Review"`, `"Code Review"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("didYouMean = %s, want it to suggest %s", got, want)
+		}
+	}
+	if n := strings.Count(got, "("); n != didYouMeanCount {
+		t.Errorf("didYouMean = %s, want %d suggestions", got, didYouMeanCount)
+	}
+}
+
+func TestScoreStatus(t *testing.T) {
+	tests := []struct {
+		status, input string
+		want          float64
+	}{
+		{"In Progress", "in progress", 1},
+		{"To Do", "ToDo", 0.95},
+		{"Done", "", 0},
+	}
+	for _, tt := range tests {
+		if got := scoreStatus(tt.status, tt.input); got != tt.want {
+			t.Errorf("scoreStatus(%q, %q) = %.2f, want %.2f", tt.status, tt.input, got, tt.want)
+		}
+	}
+}
+
+func sameNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen := map[string]int{}
+	for _, s := range a {
+		seen[s]++
+	}
+	for _, s := range b {
+		seen[s]--
+	}
+	for _, n := range seen {
+		if n != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,607 @@
+package atlassian
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	mdFenceRe     = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})\\s*([^`\\s]*)")
+	mdHeadingRe   = regexp.MustCompile(`^ {0,3}(#{1,6})(?:\s+(.*?))?(?:\s+#+)?\s*$`)
+	mdSetextRe    = regexp.MustCompile(`^ {0,3}(=+|-+)\s*$`)
+	mdRuleRe      = regexp.MustCompile(`^ {0,3}([-*_])(\s*[-*_]){2,}\s*$`)
+	mdListRe      = regexp.MustCompile(`^( *)([-*+]|\d{1,9}[.)])( +|$)(.*)$`)
+	mdQuoteRe     = regexp.MustCompile(`^ {0,3}> ?(.*)$`)
+	mdTableRuleRe = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+	mdTaskRe      = regexp.MustCompile(`^\[([ xX])\]\s+(.*)$`)
+	mdAttrRe      = regexp.MustCompile(`(\w+)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+	mdAlertRe     = regexp.MustCompile(`(?i)^\s*\[!(NOTE|TIP|IMPORTANT|WARNING|CAUTION)\]\s*$`)
+)
+
+// alertMacros maps Github alerts back to Jira's panel macros
+var alertMacros = map[string]string{
+	"NOTE":      "info",
+	"IMPORTANT": "info",
+	"TIP":       "tip",
+	"WARNING":   "note",
+	"CAUTION":   "warning",
+}
+
+// ParseMarkdown parses Github Markdown into a document
+func ParseMarkdown(md string) *Node {
+	md = strings.ReplaceAll(md, "\r\n", "\n")
+	lines := expandTabs(strings.Split(md, "\n"))
+	return &Node{Kind: DocumentNode, Children: parseMarkdownBlocks(lines)}
+}
+
+// expandTabs turns tabs into spaces so indentation can be measured,
+// except inside fenced code, which is kept as it was written.
+func expandTabs(lines []string) []string {
+	fence := ""
+	for i, line := range lines {
+		if fence != "" {
+			trimmed := strings.TrimSpace(line)
+			if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+				fence = ""
+			}
+			continue
+		}
+		lines[i] = strings.ReplaceAll(line, "\t", "    ")
+		if g := mdFenceRe.FindStringSubmatch(lines[i]); g != nil {
+			fence = g[2]
+		}
+	}
+	return lines
+}
+
+// parseMarkdownBlocks parses lines into blocks. It is used for the
+// document, and again for the insides of quotes and list items.
+func parseMarkdownBlocks(lines []string) []*Node {
+	var nodes []*Node
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		switch {
+		case strings.TrimSpace(line) == "":
+			i++
+		case mdFenceRe.MatchString(line):
+			var node *Node
+			node, i = mdCodeFence(lines, i)
+			nodes = append(nodes, node)
+		case mdHeadingRe.MatchString(line):
+			g := mdHeadingRe.FindStringSubmatch(line)
+			nodes = append(nodes, &Node{
+				Kind:     HeadingNode,
+				Level:    len(g[1]),
+				Children: parseMarkdownInline(g[2]),
+			})
+			i++
+		case mdRuleRe.MatchString(line):
+			nodes = append(nodes, &Node{Kind: RuleNode})
+			i++
+		case mdQuoteRe.MatchString(line):
+			var quoted []string
+			for ; i < len(lines) && strings.TrimSpace(lines[i]) != ""; i++ {
+				if g := mdQuoteRe.FindStringSubmatch(lines[i]); g != nil {
+					quoted = append(quoted, g[1])
+				} else {
+					// lazy continuation of the quoted paragraph
+					quoted = append(quoted, lines[i])
+				}
+			}
+			nodes = append(nodes, mdQuote(quoted))
+		case mdListRe.MatchString(line):
+			var node *Node
+			node, i = mdList(lines, i)
+			nodes = append(nodes, node)
+		case i+1 < len(lines) && strings.Contains(line, "|") && mdTableRuleRe.MatchString(lines[i+1]):
+			var node *Node
+			node, i = mdTable(lines, i)
+			nodes = append(nodes, node)
+		case strings.HasPrefix(line, "    "):
+			var code []string
+			for ; i < len(lines) && (strings.HasPrefix(lines[i], "    ") || strings.TrimSpace(lines[i]) == ""); i++ {
+				code = append(code, strings.TrimPrefix(lines[i], "    "))
+			}
+			for len(code) > 0 && strings.TrimSpace(code[len(code)-1]) == "" {
+				code = code[:len(code)-1]
+			}
+			nodes = append(nodes, &Node{Kind: CodeBlockNode, Text: strings.Join(code, "\n")})
+		default:
+			var node *Node
+			node, i = mdParagraph(lines, i)
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+func mdCodeFence(lines []string, i int) (*Node, int) {
+	g := mdFenceRe.FindStringSubmatch(lines[i])
+	indent, fence := len(g[1]), g[2]
+	node := &Node{Kind: CodeBlockNode, Language: g[3]}
+	var code []string
+	for i++; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			i++
+			break
+		}
+		line := lines[i]
+		// fences that are indented indent their code too
+		for n := 0; n < indent && strings.HasPrefix(line, " "); n++ {
+			line = line[1:]
+		}
+		code = append(code, line)
+	}
+	node.Text = strings.Join(code, "\n")
+	return node, i
+}
+
+// mdQuote parses the lines of a block quote, which might be a Github
+// alert like "> [!NOTE]". An alert that starts with a line in bold has
+// that line as its title.
+func mdQuote(lines []string) *Node {
+	g := mdAlertRe.FindStringSubmatch(lines[0])
+	if g == nil {
+		return &Node{Kind: QuoteNode, Children: parseMarkdownBlocks(lines)}
+	}
+	panel := &Node{
+		Kind:     PanelNode,
+		Macro:    alertMacros[strings.ToUpper(g[1])],
+		Children: parseMarkdownBlocks(lines[1:]),
+	}
+	if len(panel.Children) > 0 {
+		first := panel.Children[0]
+		if first.Kind == ParagraphNode && len(first.Children) == 1 &&
+			first.Children[0].Kind == StrongNode {
+			title := RenderJira(&Node{Kind: DocumentNode, Children: []*Node{
+				{Kind: ParagraphNode, Children: first.Children[0].Children},
+			}})
+			panel.Params = map[string]string{"title": title}
+			panel.Children = panel.Children[1:]
+		}
+	}
+	return panel
+}
+
+// startsBlock reports whether a line interrupts a paragraph
+func startsBlock(line string) bool {
+	return mdFenceRe.MatchString(line) || mdHeadingRe.MatchString(line) ||
+		mdQuoteRe.MatchString(line) || mdRuleRe.MatchString(line) ||
+		mdListRe.MatchString(line)
+}
+
+func mdParagraph(lines []string, i int) (*Node, int) {
+	var text []string
+	for ; i < len(lines) && strings.TrimSpace(lines[i]) != ""; i++ {
+		if len(text) > 0 && mdSetextRe.MatchString(lines[i]) {
+			level := 1
+			if strings.Contains(lines[i], "-") {
+				level = 2
+			}
+			return &Node{
+				Kind:     HeadingNode,
+				Level:    level,
+				Children: parseMarkdownInline(strings.Join(text, "\n")),
+			}, i + 1
+		}
+		if len(text) > 0 && startsBlock(lines[i]) {
+			break
+		}
+		text = append(text, strings.TrimSpace(lines[i]))
+	}
+	return &Node{Kind: ParagraphNode, Children: parseMarkdownInline(strings.Join(text, "\n"))}, i
+}
+
+// mdList parses a list, giving each item the lines indented under it,
+// which are parsed again as blocks for nested lists and paragraphs.
+func mdList(lines []string, i int) (*Node, int) {
+	g := mdListRe.FindStringSubmatch(lines[i])
+	indent := len(g[1])
+	ordered := !strings.ContainsAny(g[2], "-*+")
+	list := &Node{Kind: ListNode, Ordered: ordered}
+
+	var item []string
+	content := 0
+	flush := func() {
+		if item == nil {
+			return
+		}
+		node := &Node{Kind: ListItemNode}
+		if t := mdTaskRe.FindStringSubmatch(item[0]); t != nil {
+			node.Task, node.Checked = true, t[1] != " "
+			item[0] = t[2]
+		}
+		node.Children = parseMarkdownBlocks(item)
+		list.Children = append(list.Children, node)
+		item = nil
+	}
+
+	for i < len(lines) {
+		line := lines[i]
+		if g := mdListRe.FindStringSubmatch(line); g != nil && len(g[1]) == indent {
+			if !strings.ContainsAny(g[2], "-*+") != ordered {
+				break
+			}
+			flush()
+			content = indent + len(g[2]) + len(g[3])
+			if g[3] == "" {
+				content++
+			}
+			item = []string{g[4]}
+			i++
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			// a blank line only continues the list if what follows is indented under it
+			next := i + 1
+			for next < len(lines) && strings.TrimSpace(lines[next]) == "" {
+				next++
+			}
+			if next == len(lines) || leadingSpaces(lines[next]) < content &&
+				!(mdListRe.MatchString(lines[next]) && leadingSpaces(lines[next]) == indent) {
+				break
+			}
+			item = append(item, "")
+			i++
+			continue
+		}
+		switch {
+		case leadingSpaces(line) >= content:
+			item = append(item, line[content:])
+		case leadingSpaces(line) > indent || !startsBlock(line) && item[len(item)-1] != "":
+			// lazy continuation of the item's text
+			item = append(item, strings.TrimSpace(line))
+		default:
+			flush()
+			return list, i
+		}
+		i++
+	}
+	flush()
+	return list, i
+}
+
+func leadingSpaces(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+func mdTable(lines []string, i int) (*Node, int) {
+	table := &Node{Kind: TableNode}
+	header := &Node{Kind: TableRowNode}
+	for _, cell := range splitMarkdownRow(lines[i]) {
+		header.Children = append(header.Children,
+			&Node{Kind: TableCellNode, Header: true, Children: parseMarkdownInline(cell)})
+	}
+	table.Children = append(table.Children, header)
+	for i += 2; i < len(lines) && strings.Contains(lines[i], "|") && strings.TrimSpace(lines[i]) != ""; i++ {
+		row := &Node{Kind: TableRowNode}
+		for _, cell := range splitMarkdownRow(lines[i]) {
+			row.Children = append(row.Children,
+				&Node{Kind: TableCellNode, Children: parseMarkdownInline(cell)})
+		}
+		table.Children = append(table.Children, row)
+	}
+	return table, i
+}
+
+// splitMarkdownRow splits a table row on bars that aren't escaped
+func splitMarkdownRow(row string) []string {
+	row = strings.TrimSpace(row)
+	row = strings.TrimPrefix(row, "|")
+	if strings.HasSuffix(row, "|") && !strings.HasSuffix(row, "\\|") {
+		row = row[:len(row)-1]
+	}
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(row); i++ {
+		switch {
+		case row[i] == '\\' && i+1 < len(row) && row[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case row[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(row[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// mdInlineTags are the HTML tags JiraToMD uses for styles Markdown lacks
+var mdInlineTags = map[string]NodeKind{
+	"ins":  InsertNode,
+	"u":    InsertNode,
+	"sup":  SuperscriptNode,
+	"sub":  SubscriptNode,
+	"cite": CitationNode,
+	"del":  StrikeNode,
+	"b":    StrongNode,
+	"i":    EmphasisNode,
+}
+
+// parseMarkdownInline parses the text of a paragraph, heading or table cell
+func parseMarkdownInline(text string) []*Node {
+	return (&mdInlineParser{src: []rune(text)}).parse()
+}
+
+type mdInlineParser struct {
+	src   []rune
+	nodes []*Node
+	text  strings.Builder
+}
+
+func (p *mdInlineParser) parse() []*Node {
+	for i := 0; i < len(p.src); {
+		i = p.next(i)
+	}
+	p.flush()
+	return p.nodes
+}
+
+func (p *mdInlineParser) flush() {
+	if p.text.Len() > 0 {
+		p.nodes = append(p.nodes, &Node{Kind: TextNode, Text: p.text.String()})
+		p.text.Reset()
+	}
+}
+
+func (p *mdInlineParser) add(node *Node) {
+	p.flush()
+	p.nodes = append(p.nodes, node)
+}
+
+func (p *mdInlineParser) next(i int) int {
+	src := p.src
+	r := src[i]
+	switch {
+	case r == '\n':
+		// hard breaks end with two spaces, which we already trimmed
+		p.add(&Node{Kind: LineBreakNode})
+		return i + 1
+	case r == '\\' && i+1 < len(src):
+		if src[i+1] == '\n' {
+			p.add(&Node{Kind: LineBreakNode})
+			return i + 2
+		}
+		if src[i+1] < unicode.MaxASCII && (unicode.IsPunct(src[i+1]) || unicode.IsSymbol(src[i+1])) {
+			p.add(&Node{Kind: TextNode, Text: string(src[i+1]), Escaped: true})
+			return i + 2
+		}
+	case r == '`':
+		if end := p.codeSpan(i); end > i {
+			return end
+		}
+	case r == '!' && i+1 < len(src) && src[i+1] == '[':
+		if end := p.link(i+1, true); end > i {
+			return end
+		}
+	case r == '[':
+		if end := p.link(i, false); end > i {
+			return end
+		}
+	case r == '<':
+		if end := p.angle(i); end > i {
+			return end
+		}
+	case r == '*' || r == '_' || r == '~':
+		if end := p.emphasis(i); end > i {
+			return end
+		}
+	}
+	p.text.WriteRune(r)
+	return i + 1
+}
+
+// run counts how many times the rune at i repeats
+func (p *mdInlineParser) run(i int) int {
+	n := 0
+	for i+n < len(p.src) && p.src[i+n] == p.src[i] {
+		n++
+	}
+	return n
+}
+
+func (p *mdInlineParser) codeSpan(i int) int {
+	n := p.run(i)
+	for j := i + n; j < len(p.src); {
+		if p.src[j] != '`' {
+			j++
+			continue
+		}
+		m := p.run(j)
+		if m == n {
+			code := string(p.src[i+n : j])
+			if len(code) > 1 && code[0] == ' ' && code[len(code)-1] == ' ' {
+				code = code[1 : len(code)-1]
+			}
+			p.add(&Node{Kind: MonospaceNode, Text: strings.ReplaceAll(code, "\n", " ")})
+			return j + m
+		}
+		j += m
+	}
+	return i
+}
+
+// link handles [text](url) and, for images, ![alt](url)
+func (p *mdInlineParser) link(i int, image bool) int {
+	src := p.src
+	depth, close := 0, -1
+	for j := i + 1; j < len(src) && close < 0; j++ {
+		switch src[j] {
+		case '\\':
+			j++
+		case '[':
+			depth++
+		case ']':
+			if depth == 0 {
+				close = j
+			}
+			depth--
+		}
+	}
+	if close < 0 || close+1 >= len(src) || src[close+1] != '(' {
+		return i
+	}
+	end := -1
+	for j, depth := close+2, 0; j < len(src); j++ {
+		if src[j] == '(' {
+			depth++
+		} else if src[j] == ')' {
+			if depth == 0 {
+				end = j
+				break
+			}
+			depth--
+		}
+	}
+	if end < 0 {
+		return i
+	}
+	target := strings.TrimSpace(string(src[close+2 : end]))
+	// drop any title, like [text](url "title")
+	if sp := strings.IndexAny(target, " \t"); sp >= 0 {
+		target = target[:sp]
+	}
+	target = strings.TrimSuffix(strings.TrimPrefix(target, "<"), ">")
+	text := string(src[i+1 : close])
+	if image {
+		node := &Node{Kind: ImageNode, URL: target}
+		if text != "" {
+			node.Params = map[string]string{"alt": text}
+		}
+		p.add(node)
+	} else {
+		p.add(&Node{Kind: LinkNode, URL: target, Children: parseMarkdownInline(text)})
+	}
+	return end + 1
+}
+
+// angle handles <http://autolinks> and the HTML tags JiraToMD writes
+func (p *mdInlineParser) angle(i int) int {
+	rest := string(p.src[i:])
+	end := strings.IndexByte(rest, '>')
+	if end < 0 {
+		return i
+	}
+	inner := rest[1:end]
+	if strings.Contains(inner, "://") && !strings.ContainsAny(inner, " <") || strings.HasPrefix(inner, "mailto:") {
+		p.add(&Node{Kind: LinkNode, URL: inner})
+		return i + len([]rune(rest[:end+1]))
+	}
+	tag := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(inner), "/"))
+	if tag == "br" {
+		p.add(&Node{Kind: LineBreakNode})
+		return i + len([]rune(rest[:end+1]))
+	}
+	if strings.HasPrefix(tag, "img ") {
+		if image := mdImageTag(inner); image != nil {
+			p.add(image)
+			return i + len([]rune(rest[:end+1]))
+		}
+		return i
+	}
+	kind, ok := mdInlineTags[tag]
+	if !ok {
+		return i
+	}
+	closeTag := "</" + tag + ">"
+	closeAt := strings.Index(strings.ToLower(rest), closeTag)
+	if closeAt < 0 {
+		return i
+	}
+	p.add(&Node{Kind: kind, Children: parseMarkdownInline(rest[end+1 : closeAt])})
+	return i + len([]rune(rest[:closeAt+len(closeTag)]))
+}
+
+// mdImageTag parses the inside of an <img> tag, keeping its size and alt text
+func mdImageTag(inner string) *Node {
+	image := &Node{Kind: ImageNode, Params: map[string]string{}}
+	for _, g := range mdAttrRe.FindAllStringSubmatch(inner, -1) {
+		name, value := strings.ToLower(g[1]), html.UnescapeString(g[2]+g[3])
+		switch name {
+		case "src":
+			image.URL = value
+		case "width", "height", "alt":
+			image.Params[name] = value
+		}
+	}
+	if image.URL == "" {
+		return nil
+	}
+	return image
+}
+
+// emphasis handles *em*, _em_, **strong**, __strong__, ***both*** and ~~strike~~
+func (p *mdInlineParser) emphasis(i int) int {
+	src := p.src
+	r := src[i]
+	n := p.run(i)
+	if r == '~' && n > 2 || n > 3 {
+		return i
+	}
+	start := i + n
+	if start >= len(src) || unicode.IsSpace(src[start]) {
+		return i
+	}
+	if r == '_' && i > 0 && isWordRune(src[i-1]) {
+		// intraword underscores, like snake_case, aren't emphasis
+		return i
+	}
+	// runs that opened emphasis inside ours, so **a *b*** can close both
+	var inner []int
+	for j := start; j < len(src); {
+		if src[j] == '`' {
+			// nothing inside a code span can close emphasis
+			if m := p.run(j); m > 0 {
+				if end := strings.Index(string(src[j+m:]), strings.Repeat("`", m)); end >= 0 {
+					j += m + len([]rune(string(src[j+m:])[:end])) + m
+					continue
+				}
+			}
+		}
+		if src[j] != r {
+			j++
+			continue
+		}
+		m := p.run(j)
+		closes := !unicode.IsSpace(src[j-1]) &&
+			!(r == '_' && j+m < len(src) && isWordRune(src[j+m]))
+		last := len(inner) - 1
+		end := j
+		switch {
+		case closes && last >= 0 && inner[last] == m:
+			inner = inner[:last]
+			j += m
+			continue
+		case closes && m == n:
+		case closes && last == 0 && m == n+inner[0]:
+			// the end of the run closes ours, the start closes the inner one
+			end = j + m - n
+		default:
+			if j+m < len(src) && !unicode.IsSpace(src[j+m]) {
+				inner = append(inner, m)
+			}
+			j += m
+			continue
+		}
+		children := parseMarkdownInline(string(src[start:end]))
+		var node *Node
+		switch {
+		case r == '~':
+			node = &Node{Kind: StrikeNode, Children: children}
+		case n == 1:
+			node = &Node{Kind: EmphasisNode, Children: children}
+		case n == 2:
+			node = &Node{Kind: StrongNode, Children: children}
+		default:
+			node = &Node{Kind: StrongNode, Children: []*Node{{Kind: EmphasisNode, Children: children}}}
+		}
+		p.add(node)
+		return j + m
+	}
+	return i
+}
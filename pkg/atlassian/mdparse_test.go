@@ -0,0 +1,24 @@
+package atlassian
+
+import "testing"
+
+func TestMarkdownEmphasis(t *testing.T) {
+	tests := []struct {
+		md, want string
+	}{
+		{"**bold** *it*", "*bold* _it_"},
+		{"***both***", "*_both_*"},
+		{"**a *b* c**", "*a _b_ c*"},
+		{"*a **b** c*", "_a *b* c_"},
+		{"**bold *nested***", "*bold _nested_*"},
+		{"*nested **bold***", "_nested *bold*_"},
+		{"~~gone~~", "-gone-"},
+		{"snake_case_name", "snake_case_name"},
+		{"2 * 3 * 4", "2 * 3 * 4"},
+	}
+	for _, tt := range tests {
+		if got := MarkdownToJira(tt.md); got != tt.want {
+			t.Errorf("MarkdownToJira(%q) = %q, want %q", tt.md, got, tt.want)
+		}
+	}
+}
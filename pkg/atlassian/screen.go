@@ -0,0 +1,129 @@
+package atlassian
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+// AllowedValue is one of the choices for a field like Resolution
+type AllowedValue struct {
+	ID    string `json:"id"`
+	Name  string `json:"name,omitempty"`
+	Value string `json:"value,omitempty"`
+}
+
+// String returns what a person would call this choice
+func (v AllowedValue) String() string {
+	if v.Name != "" {
+		return v.Name
+	}
+	if v.Value != "" {
+		return v.Value
+	}
+	return v.ID
+}
+
+// TransitionField describes a field on a transition's screen
+type TransitionField struct {
+	Key             string           `json:"key"`
+	Name            string           `json:"name"`
+	Required        bool             `json:"required"`
+	HasDefaultValue bool             `json:"hasDefaultValue"`
+	Schema          jira.FieldSchema `json:"schema"`
+	AllowedValues   []AllowedValue   `json:"allowedValues,omitempty"`
+}
+
+// TransitionDetails is a transition along with its screen and field metadata,
+// which go-jira's Transition leaves out.
+type TransitionDetails struct {
+	ID        string                     `json:"id"`
+	Name      string                     `json:"name"`
+	To        jira.Status                `json:"to"`
+	HasScreen bool                       `json:"hasScreen"`
+	Fields    map[string]TransitionField `json:"fields"`
+}
+
+// GetTransitionDetails fetches the transitions available for an issue,
+// with their fields. If transitionID is not empty, only that one is fetched.
+func GetTransitionDetails(
+	jiraClient *jira.Client,
+	issueKey string,
+	transitionID string,
+) ([]TransitionDetails, error) {
+	apiEndpoint := fmt.Sprintf("rest/api/2/issue/%s/transitions?expand=transitions.fields",
+		url.PathEscape(issueKey))
+	if transitionID != "" {
+		apiEndpoint += "&transitionId=" + url.QueryEscape(transitionID)
+	}
+	var result struct {
+		Transitions []TransitionDetails `json:"transitions"`
+	}
+	if err := getJSON(jiraClient, apiEndpoint, &result); err != nil {
+		return nil, err
+	}
+	for _, t := range result.Transitions {
+		for key, field := range t.Fields {
+			field.Key = key
+			t.Fields[key] = field
+		}
+	}
+	return result.Transitions, nil
+}
+
+// encodeFieldValue turns what the user typed into the JSON Jira expects for
+// the field's type. Multi-valued fields take a comma separated list.
+func encodeFieldValue(field TransitionField, value string) (interface{}, error) {
+	if field.Schema.Type == "array" {
+		var values []interface{}
+		for _, v := range strings.Split(value, ",") {
+			item := TransitionField{
+				Name:          field.Name,
+				Schema:        jira.FieldSchema{Type: field.Schema.Items},
+				AllowedValues: field.AllowedValues,
+			}
+			encoded, err := encodeFieldValue(item, strings.TrimSpace(v))
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, encoded)
+		}
+		return values, nil
+	}
+
+	if len(field.AllowedValues) > 0 {
+		for _, allowed := range field.AllowedValues {
+			if strings.EqualFold(allowed.ID, value) ||
+				strings.EqualFold(allowed.Name, value) ||
+				strings.EqualFold(allowed.Value, value) {
+				return map[string]string{"id": allowed.ID}, nil
+			}
+		}
+		choices := make([]string, len(field.AllowedValues))
+		for i, allowed := range field.AllowedValues {
+			choices[i] = allowed.String()
+		}
+		return nil, fmt.Errorf("%q is not a valid %s, expected one of: %s",
+			value, field.Name, strings.Join(choices, ", "))
+	}
+
+	switch field.Schema.Type {
+	case "string", "date", "datetime", "any", "":
+		return value, nil
+	case "number":
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a number: %w", field.Name, err)
+		}
+		return n, nil
+	case "user":
+		return map[string]string{"accountId": value}, nil
+	case "option":
+		return map[string]string{"value": value}, nil
+	default:
+		return map[string]string{"name": value}, nil
+	}
+}
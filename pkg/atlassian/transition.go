@@ -0,0 +1,343 @@
+package atlassian
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+// TransitionChooser asks the user to pick one of several candidate transitions.
+// It returns nil if the user declined to choose.
+type TransitionChooser func(candidates []jira.Transition) (*jira.Transition, error)
+
+// TransitionConfirmer asks the user whether to walk an issue through a
+// series of statuses. The path starts with the issue's current status.
+type TransitionConfirmer func(issueKey string, path []string) bool
+
+// FieldPrompter asks the user for the value of a required transition field
+type FieldPrompter func(field TransitionField) (string, error)
+
+// TransitionOptions control how MoveIssueToStatusByName gets an issue
+// to its new status. The zero value only makes direct, unambiguous moves.
+type TransitionOptions struct {
+	// Choose picks between ambiguous transitions. If nil, ambiguity is an error.
+	Choose TransitionChooser
+	// MaxHops is the most transitions we will chain together to reach a
+	// status that is not directly reachable. Less than 2 disables this.
+	MaxHops int
+	// Confirm is asked before any multi-step move. If nil, those are refused.
+	Confirm TransitionConfirmer
+	// Fields are values for the final transition's screen, keyed by field name or ID.
+	Fields map[string]string
+	// Comment is added to the issue along with the final transition.
+	Comment string
+	// Prompt asks for required fields we have no value for. If nil, those are an error.
+	Prompt FieldPrompter
+	// Aliases are resolved before matching statusName.
+	Aliases StatusAliases
+	// Journal records each move so it can be undone. It may be nil.
+	Journal *Journal
+	// Hooks are run before and after moving to their status.
+	Hooks Hooks
+	// DryRun describes the hooks instead of running them.
+	DryRun bool
+}
+
+// MoveIssueToStatusByName transitions an issue to the status matching statusName.
+// If statusName is empty or matches several transitions, opts.Choose is asked
+// to pick one. If no transition leads there directly, it looks for a path
+// through the workflow of at most opts.MaxHops transitions.
+func MoveIssueToStatusByName(
+	jiraClient *jira.Client,
+	issue *jira.Issue,
+	issueKey string,
+	statusName string,
+	opts TransitionOptions,
+) error {
+	projectKey := issue.Fields.Project.Key
+	if projectKey == "" {
+		projectKey = ProjectKey(issueKey)
+	}
+	statusName = opts.Aliases.Resolve(projectKey, statusName)
+	originalStatus := issue.Fields.Status.Name
+	if statusName != "" && (issue.Fields.Status.Name == statusName ||
+		caseInsensitiveContains(issue.Fields.Status.Name, statusName)) {
+		return fmt.Errorf("issue is Already in Status %s\n", issue.Fields.Status.Name)
+	}
+
+	possibleTransitions, _, err := jiraClient.Issue.GetTransitions(issueKey)
+	if err != nil {
+		return err
+	}
+	candidates := possibleTransitions
+	if statusName != "" {
+		candidates = nil
+		for _, v := range MatchTransitions(possibleTransitions, statusName) {
+			candidates = append(candidates, v.Transition)
+		}
+	}
+
+	var transition *jira.Transition
+	switch {
+	case len(candidates) == 0 && statusName == "":
+		return fmt.Errorf("there are no transitions available for %s", issueKey)
+	case len(candidates) == 0 && opts.MaxHops > 1:
+		// nothing gets us there in one step, so see if a few steps will
+		pathErr := moveIssueAlongPath(jiraClient, issue, issueKey, projectKey, statusName, opts)
+		if pathErr != nil && len(possibleTransitions) > 0 {
+			return fmt.Errorf("there does not appear to be a valid transition to %s, did you mean: %s\n%v",
+				statusName, DidYouMean(RankTransitions(possibleTransitions, statusName)), pathErr)
+		}
+		return pathErr
+	case len(candidates) == 0 && len(possibleTransitions) == 0:
+		return fmt.Errorf("there does not appear to be a valid transition to %s", statusName)
+	case len(candidates) == 0:
+		// still no match. sigh. give up, but help them try again.
+		return fmt.Errorf("there does not appear to be a valid transition to %s, did you mean: %s",
+			statusName, DidYouMean(RankTransitions(possibleTransitions, statusName)))
+	case len(candidates) == 1 && statusName != "":
+		transition = &candidates[0]
+	case opts.Choose == nil && statusName == "":
+		return fmt.Errorf("you need to pass a desired jira status for %s", issueKey)
+	case opts.Choose == nil:
+		return fmt.Errorf("%s is ambiguous, did you mean: %s",
+			statusName, DidYouMean(RankTransitions(possibleTransitions, statusName)))
+	default:
+		transition, err = opts.Choose(candidates)
+		if err != nil {
+			return err
+		}
+		if transition == nil {
+			return fmt.Errorf("no transition chosen for %s", issueKey)
+		}
+	}
+
+	hooks := newHookContext(issue, issueKey, projectKey, transition.To.Name, opts.DryRun)
+	if err = opts.Hooks.runPre(hooks); err != nil {
+		return err
+	}
+	err = doTransition(jiraClient, issueKey, transition.ID, opts, true)
+	if err != nil {
+		return err
+	}
+	if opts.DryRun {
+		// nothing changed, so there is nothing to check
+		fmt.Printf("Issue %s would change from: %s to: %s\n",
+			issueKey, originalStatus, transition.To.Name)
+		opts.Hooks.runPost(hooks)
+		return nil
+	}
+	issue, _, err = jiraClient.Issue.Get(issueKey, nil)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Issue %s Status successfully changed from: %s and set to: %+v\n",
+		issueKey, originalStatus, issue.Fields.Status.Name)
+	recordTransition(opts.Journal, issueKey, originalStatus, issue.Fields.Status.Name, transition.Name)
+	opts.Hooks.runPost(hooks)
+
+	return nil
+}
+
+func recordTransition(journal *Journal, issueKey, from, to, transition string) {
+	err := journal.Record(JournalEntry{
+		IssueKey:   issueKey,
+		Action:     JournalTransition,
+		FromStatus: from,
+		ToStatus:   to,
+		Transition: transition,
+	})
+	if err != nil {
+		fmt.Printf("Unable to record change in journal: %v\n", err)
+	}
+}
+
+// moveIssueAlongPath walks an issue through the workflow one transition at
+// a time. It stops at the first hop that fails, leaving the issue wherever
+// it got to, and says so.
+func moveIssueAlongPath(
+	jiraClient *jira.Client,
+	issue *jira.Issue,
+	issueKey string,
+	projectKey string,
+	statusName string,
+	opts TransitionOptions,
+) error {
+	path, err := FindStatusPath(jiraClient, issue, statusName, opts.MaxHops)
+	if err != nil {
+		return err
+	}
+	if len(path) == 0 || path[len(path)-1].ID == issue.Fields.Status.ID {
+		return fmt.Errorf("issue is already in status %s", issue.Fields.Status.Name)
+	}
+	target := path[len(path)-1]
+	names := []string{issue.Fields.Status.Name}
+	for _, s := range path {
+		names = append(names, s.Name)
+	}
+	if opts.Confirm == nil || !opts.Confirm(issueKey, names) {
+		return fmt.Errorf("not moving %s through %s", issueKey, strings.Join(names, " -> "))
+	}
+	// hooks are about where the issue ends up, not the statuses along the way
+	hooks := newHookContext(issue, issueKey, projectKey, target.Name, opts.DryRun)
+	if err = opts.Hooks.runPre(hooks); err != nil {
+		return err
+	}
+
+	current := issue.Fields.Status.Name
+	var taken []string
+	// however far we get, journal it so it can be undone
+	defer func() {
+		if len(taken) > 0 {
+			recordTransition(opts.Journal, issueKey, names[0], current, strings.Join(taken, " -> "))
+		}
+	}()
+	for i, hop := range path {
+		if opts.DryRun && i > 0 {
+			// the issue hasn't really moved, so Jira can't tell us the later transitions
+			fmt.Printf("Issue %s would then move from %s to %s\n", issueKey, current, hop.Name)
+			current = hop.Name
+			continue
+		}
+		possibleTransitions, _, err := jiraClient.Issue.GetTransitions(issueKey)
+		if err != nil {
+			return fmt.Errorf("stopped %s at %s after %d of %d hops: %w",
+				issueKey, current, i, len(path), err)
+		}
+		transition := transitionToStatus(possibleTransitions, hop.ID)
+		if transition == nil {
+			return fmt.Errorf("stopped %s at %s after %d of %d hops: no transition to %s",
+				issueKey, current, i, len(path), hop.Name)
+		}
+		err = doTransition(jiraClient, issueKey, transition.ID, opts, i == len(path)-1)
+		if err != nil {
+			return fmt.Errorf("stopped %s at %s after %d of %d hops: %w",
+				issueKey, current, i, len(path), err)
+		}
+		if opts.DryRun {
+			fmt.Printf("Issue %s would move from %s to %s\n", issueKey, current, hop.Name)
+		} else {
+			fmt.Printf("Issue %s moved from %s to %s\n", issueKey, current, hop.Name)
+		}
+		current = hop.Name
+		taken = append(taken, transition.Name)
+	}
+	if opts.DryRun {
+		fmt.Printf("Issue %s would change from: %s to: %s via %s\n",
+			issueKey, names[0], current, strings.Join(names, " -> "))
+	} else {
+		fmt.Printf("Issue %s Status successfully changed from: %s and set to: %s via %s\n",
+			issueKey, names[0], current, strings.Join(names, " -> "))
+	}
+	opts.Hooks.runPost(hooks)
+
+	return nil
+}
+
+func transitionToStatus(possibleTransitions []jira.Transition, statusID string) *jira.Transition {
+	for i, v := range possibleTransitions {
+		if v.To.ID == statusID {
+			return &possibleTransitions[i]
+		}
+	}
+	return nil
+}
+
+// doTransition performs a transition, filling in its screen. The fields and
+// comment in opts are only used for the final transition of a move, but
+// required fields are prompted for on every one.
+func doTransition(
+	jiraClient *jira.Client,
+	issueKey string,
+	transitionID string,
+	opts TransitionOptions,
+	final bool,
+) error {
+	details, err := GetTransitionDetails(jiraClient, issueKey, transitionID)
+	if err != nil {
+		return err
+	}
+	if len(details) == 0 {
+		return fmt.Errorf("transition %s is not available for %s", transitionID, issueKey)
+	}
+	meta := details[0]
+
+	given := map[string]string{}
+	comment := ""
+	if final {
+		given = opts.Fields
+		comment = opts.Comment
+	}
+	used := map[string]bool{}
+	fields := map[string]interface{}{}
+	for key, field := range meta.Fields {
+		value, name, ok := lookupField(given, key, field.Name)
+		if ok {
+			used[name] = true
+		}
+		if key == "comment" {
+			// comments are added through "update", not set through "fields"
+			if comment == "" && ok {
+				comment = value
+			}
+			ok = comment != ""
+		}
+		if !ok {
+			if !field.Required || field.HasDefaultValue {
+				continue
+			}
+			if opts.Prompt == nil {
+				return fmt.Errorf("transition %s requires %s", meta.Name, field.Name)
+			}
+			value, err = opts.Prompt(field)
+			if err != nil {
+				return err
+			}
+			if value == "" {
+				return fmt.Errorf("transition %s requires %s", meta.Name, field.Name)
+			}
+			if key == "comment" {
+				comment = value
+			}
+		}
+		if key == "comment" {
+			continue
+		}
+		fields[key], err = encodeFieldValue(field, value)
+		if err != nil {
+			return err
+		}
+	}
+	for name := range given {
+		if !used[name] {
+			return fmt.Errorf("%s is not a field on the %s transition screen", name, meta.Name)
+		}
+	}
+
+	payload := map[string]interface{}{
+		"transition": map[string]string{"id": transitionID},
+	}
+	if len(fields) > 0 {
+		payload["fields"] = fields
+	}
+	if comment != "" {
+		payload["update"] = map[string]interface{}{
+			"comment": []interface{}{
+				map[string]interface{}{"add": map[string]string{"body": comment}},
+			},
+		}
+	}
+	_, err = jiraClient.Issue.DoTransitionWithPayload(issueKey, payload)
+	return err
+}
+
+// lookupField finds the value given for a field by its ID or its name
+func lookupField(given map[string]string, key, name string) (value, givenAs string, ok bool) {
+	for k, v := range given {
+		if strings.EqualFold(k, key) || strings.EqualFold(k, name) {
+			return v, k, true
+		}
+	}
+	return "", "", false
+}
@@ -0,0 +1,162 @@
+package atlassian
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+// IssueNode is an issue in a hierarchy, along with the issues related to it
+type IssueNode struct {
+	Key     string
+	Summary string
+	Status  string
+	// Relation is how this issue relates to the one above it in the tree,
+	// like "parent", "epic", "subtask" or "is blocked by"
+	Relation string
+	// Seen means this issue is already elsewhere in the tree, so its
+	// relations are not repeated here
+	Seen     bool
+	Err      error
+	Children []*IssueNode
+}
+
+// GetIssueTree fetches an issue along with its parent, epic, subtasks and
+// linked issues. Links are followed depth levels deep, fetching each level
+// concurrently.
+func GetIssueTree(jiraClient *jira.Client, issueKey string, depth int) (*IssueNode, error) {
+	issue, _, err := jiraClient.Issue.Get(issueKey, &jira.GetQueryOptions{Expand: "names"})
+	if err != nil {
+		return nil, err
+	}
+	t := &treeBuilder{jiraClient: jiraClient, seen: map[string]bool{issue.Key: true}}
+	root := newIssueNode(issue.Key, issue.Fields, "")
+	t.addRelations(root, issue, depth, true)
+	return root, nil
+}
+
+type treeBuilder struct {
+	jiraClient *jira.Client
+	mu         sync.Mutex
+	seen       map[string]bool
+}
+
+// claim marks an issue as placed in the tree, reporting whether it was new
+func (t *treeBuilder) claim(key string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.seen[key] {
+		return false
+	}
+	t.seen[key] = true
+	return true
+}
+
+func newIssueNode(key string, fields *jira.IssueFields, relation string) *IssueNode {
+	node := &IssueNode{Key: key, Relation: relation}
+	if fields != nil {
+		node.Summary = fields.Summary
+		if fields.Status != nil {
+			node.Status = fields.Status.Name
+		}
+	}
+	return node
+}
+
+// addRelations fills in node's children from issue. The hierarchy (parent
+// and epic) is only shown for the issue we started from.
+func (t *treeBuilder) addRelations(node *IssueNode, issue *jira.Issue, depth int, hierarchy bool) {
+	var wg sync.WaitGroup
+	// fetch fills in a child node whose summary and status we don't have yet,
+	// and follows its links if we haven't gone deep enough.
+	fetch := func(child *IssueNode, follow bool) {
+		defer wg.Done()
+		related := t.fill(child, nil)
+		if related != nil && follow {
+			t.addRelations(child, related, depth-1, false)
+		}
+	}
+
+	if hierarchy {
+		epicKey := epicLink(issue)
+		if issue.Fields.Parent != nil && t.claim(issue.Fields.Parent.Key) {
+			child := &IssueNode{Key: issue.Fields.Parent.Key, Relation: "parent"}
+			node.Children = append(node.Children, child)
+			// with names, so we can find the parent's epic link
+			parent := t.fill(child, &jira.GetQueryOptions{Expand: "names"})
+			if epicKey == "" && parent != nil && child.Relation != "epic" {
+				// a subtask's epic is its parent's
+				epicKey = epicLink(parent)
+			}
+		}
+		if epicKey != "" && t.claim(epicKey) {
+			child := &IssueNode{Key: epicKey, Relation: "epic"}
+			node.Children = append(node.Children, child)
+			wg.Add(1)
+			go fetch(child, false)
+		}
+	}
+
+	for _, subtask := range issue.Fields.Subtasks {
+		child := newIssueNode(subtask.Key, &subtask.Fields, "subtask")
+		child.Seen = !t.claim(subtask.Key)
+		node.Children = append(node.Children, child)
+	}
+
+	for _, link := range issue.Fields.IssueLinks {
+		related, relation := link.OutwardIssue, link.Type.Outward
+		if related == nil {
+			related, relation = link.InwardIssue, link.Type.Inward
+		}
+		if related == nil {
+			continue
+		}
+		child := newIssueNode(related.Key, related.Fields, relation)
+		node.Children = append(node.Children, child)
+		if !t.claim(related.Key) {
+			child.Seen = true
+			continue
+		}
+		if depth > 1 {
+			wg.Add(1)
+			go fetch(child, true)
+		}
+	}
+	wg.Wait()
+}
+
+// fill fetches the issue a child node stands for, to fill in its summary
+// and status. It returns nil if the issue can't be fetched.
+func (t *treeBuilder) fill(child *IssueNode, options *jira.GetQueryOptions) *jira.Issue {
+	related, _, err := t.jiraClient.Issue.Get(child.Key, options)
+	if err != nil {
+		child.Err = err
+		return nil
+	}
+	child.Summary = related.Fields.Summary
+	if related.Fields.Status != nil {
+		child.Status = related.Fields.Status.Name
+	}
+	if child.Relation == "parent" && related.Fields.Type.Name == "Epic" {
+		child.Relation = "epic"
+	}
+	return related
+}
+
+// epicLink finds the issue's epic, which depending on the Jira instance is
+// either the epic field or a custom field named "Epic Link".
+func epicLink(issue *jira.Issue) string {
+	if issue.Fields.Epic != nil && issue.Fields.Epic.Key != "" {
+		return issue.Fields.Epic.Key
+	}
+	for id, name := range issue.Names {
+		if !strings.EqualFold(name, "Epic Link") {
+			continue
+		}
+		if key, ok := issue.Fields.Unknowns[id].(string); ok {
+			return key
+		}
+	}
+	return ""
+}
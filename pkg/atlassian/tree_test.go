@@ -0,0 +1,57 @@
+package atlassian
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+// testIssues are the issues served by testJira, by key
+var testIssues = map[string]string{
+	"TEAM-3": `{"key": "TEAM-3", "fields": {"summary": "Write the helper",
+		"status": {"name": "To Do"}, "issuetype": {"name": "Sub-task"},
+		"parent": {"key": "TEAM-2"}}}`,
+	"TEAM-2": `{"key": "TEAM-2", "names": {"customfield_10014": "Epic Link"},
+		"fields": {"summary": "Add retries", "status": {"name": "In Progress"},
+		"issuetype": {"name": "Story"}, "customfield_10014": "TEAM-1"}}`,
+	"TEAM-1": `{"key": "TEAM-1", "fields": {"summary": "Reliable uploads",
+		"status": {"name": "In Progress"}, "issuetype": {"name": "Epic"}}}`,
+	"OPS-1": `{"key": "OPS-1", "fields": {"summary": "Raise the upload limit",
+		"status": {"name": "Done"}, "issuetype": {"name": "Task"}}}`,
+}
+
+func testJira(t *testing.T) *jira.Client {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		issue, ok := testIssues[key]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(issue))
+	}))
+	t.Cleanup(srv.Close)
+	jiraClient, err := jira.NewClient(nil, srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return jiraClient
+}
+
+func TestGetIssueTreeSubtaskEpic(t *testing.T) {
+	tree, err := GetIssueTree(testJira(t), "TEAM-3", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, child := range tree.Children {
+		got = append(got, child.Relation+": "+child.Key+" "+child.Summary)
+	}
+	want := []string{"parent: TEAM-2 Add retries", "epic: TEAM-1 Reliable uploads"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("GetIssueTree(TEAM-3) children = %q, want %q", got, want)
+	}
+}
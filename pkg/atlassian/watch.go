@@ -0,0 +1,113 @@
+package atlassian
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+// IssueChange is a field that changed between two looks at an issue
+type IssueChange struct {
+	Field string
+	From  string
+	To    string
+	// Long means the values are too long to show, like an edited description
+	Long bool
+}
+
+// unwatchedFields change without anyone editing the issue, or are shown some other way
+var unwatchedFields = map[string]bool{
+	"updated":           true,
+	"lastViewed":        true,
+	"comment":           true,
+	"watches":           true,
+	"worklog":           true,
+	"progress":          true,
+	"aggregateprogress": true,
+}
+
+// DiffIssues lists the fields that changed between two fetches of an issue,
+// status and assignee first. Comments are left to NewComments.
+func DiffIssues(before, after *jira.Issue) ([]IssueChange, error) {
+	old, err := FieldValues(before)
+	if err != nil {
+		return nil, err
+	}
+	current, err := FieldValues(after)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for id := range current {
+		ids = append(ids, id)
+	}
+	for id := range old {
+		if _, ok := current[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if watchRank(ids[i]) != watchRank(ids[j]) {
+			return watchRank(ids[i]) < watchRank(ids[j])
+		}
+		return ids[i] < ids[j]
+	})
+
+	var changes []IssueChange
+	for _, id := range ids {
+		if unwatchedFields[id] || reflect.DeepEqual(old[id], current[id]) {
+			continue
+		}
+		name := after.Names[id]
+		if name == "" {
+			name = id
+		}
+		from, to := formatValue(old[id]), formatValue(current[id])
+		changes = append(changes, IssueChange{
+			Field: name,
+			From:  from,
+			To:    to,
+			Long:  isLong(from) || isLong(to),
+		})
+	}
+	return changes, nil
+}
+
+// watchRank puts the changes people care about most first
+func watchRank(id string) int {
+	switch id {
+	case "status":
+		return 0
+	case "assignee":
+		return 1
+	default:
+		return 2
+	}
+}
+
+func isLong(value string) bool {
+	return len(value) > 60 || strings.Contains(value, "\n")
+}
+
+// NewComments returns the comments on after that were not on before
+func NewComments(before, after *jira.Issue) []*jira.Comment {
+	if after.Fields.Comments == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	if before.Fields.Comments != nil {
+		for _, c := range before.Fields.Comments.Comments {
+			seen[c.ID] = true
+		}
+	}
+	var comments []*jira.Comment
+	for _, c := range after.Fields.Comments.Comments {
+		if !seen[c.ID] {
+			comments = append(comments, c)
+		}
+	}
+	return comments
+}
@@ -0,0 +1,171 @@
+package atlassian
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+// workflowScheme maps an issue type ID to the name of its workflow
+type workflowScheme struct {
+	DefaultWorkflow   string            `json:"defaultWorkflow"`
+	IssueTypeMappings map[string]string `json:"issueTypeMappings"`
+}
+
+type workflowSchemeAssociations struct {
+	Values []struct {
+		WorkflowScheme workflowScheme `json:"workflowScheme"`
+	} `json:"values"`
+}
+
+// workflowTransition is one edge of a workflow. Its Type is "global" for
+// transitions available from every status, "directed" for ones from the
+// statuses in From, or "initial" for the one that creates the issue.
+type workflowTransition struct {
+	ID   string   `json:"id"`
+	Name string   `json:"name"`
+	Type string   `json:"type"`
+	From []string `json:"from"`
+	To   string   `json:"to"`
+}
+
+type workflow struct {
+	Transitions []workflowTransition `json:"transitions"`
+	Statuses    []jira.Status        `json:"statuses"`
+}
+
+type workflowSearch struct {
+	Values []workflow `json:"values"`
+}
+
+// FindStatusPath works out the shortest series of statuses an issue has to pass
+// through to reach statusName, using the workflow definition for the issue's
+// project and type. The issue's current status is not included in the path.
+// It gives up if the path would need more than maxHops transitions.
+func FindStatusPath(
+	jiraClient *jira.Client,
+	issue *jira.Issue,
+	statusName string,
+	maxHops int,
+) ([]jira.Status, error) {
+	wf, err := getIssueWorkflow(jiraClient, issue)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the workflow for %s: %w", issue.Key, err)
+	}
+	return wf.statusPath(issue, statusName, maxHops)
+}
+
+// statusPath is FindStatusPath once we have the workflow
+func (wf *workflow) statusPath(issue *jira.Issue, statusName string, maxHops int) ([]jira.Status, error) {
+	// Treat every status as if it were a transition so we can reuse the matcher
+	asTransitions := make([]jira.Transition, len(wf.Statuses))
+	for i, s := range wf.Statuses {
+		asTransitions[i] = jira.Transition{To: s}
+	}
+	matches := MatchTransitions(asTransitions, statusName)
+	if len(matches) != 1 {
+		return nil, fmt.Errorf("%s does not clearly match any status in the workflow, did you mean: %s",
+			statusName, DidYouMean(RankTransitions(asTransitions, statusName)))
+	}
+	target := matches[0].To
+	if target.ID == issue.Fields.Status.ID {
+		return nil, fmt.Errorf("issue is already in status %s", issue.Fields.Status.Name)
+	}
+
+	path := wf.shortestPath(issue.Fields.Status.ID, target.ID, maxHops)
+	if path == nil {
+		return nil, fmt.Errorf("%s cannot reach %s within %d transitions",
+			issue.Key, target.Name, maxHops)
+	}
+	return path, nil
+}
+
+// shortestPath finds the fewest statuses to pass through to get from the
+// start status to the target, not counting the start, or nil if the target
+// can't be reached within maxHops transitions.
+func (wf *workflow) shortestPath(start, targetID string, maxHops int) []jira.Status {
+	statuses := make(map[string]jira.Status, len(wf.Statuses))
+	for _, s := range wf.Statuses {
+		statuses[s.ID] = s
+	}
+
+	// breadth-first, so the first time we reach the target it is by the fewest hops
+	previous := map[string]string{start: ""}
+	queue := []string{start}
+	for depth := 0; len(queue) > 0 && depth < maxHops; depth++ {
+		var next []string
+		for _, from := range queue {
+			for _, t := range wf.Transitions {
+				if _, seen := previous[t.To]; seen || !transitionStartsAt(t, from) {
+					continue
+				}
+				previous[t.To] = from
+				next = append(next, t.To)
+			}
+		}
+		queue = next
+		if _, found := previous[targetID]; found {
+			break
+		}
+	}
+	if _, found := previous[targetID]; !found {
+		return nil
+	}
+
+	path := []jira.Status{}
+	for id := targetID; id != start; id = previous[id] {
+		path = append([]jira.Status{statuses[id]}, path...)
+	}
+	return path
+}
+
+func transitionStartsAt(t workflowTransition, statusID string) bool {
+	switch {
+	case t.Type == "initial":
+		// creating the issue isn't a way to move it
+		return false
+	case t.Type == "global" || len(t.From) == 0:
+		return true
+	}
+	for _, from := range t.From {
+		if from == statusID {
+			return true
+		}
+	}
+	return false
+}
+
+// getIssueWorkflow looks up which workflow applies to the issue's type in
+// its project, then fetches that workflow's statuses and transitions.
+// Note that Jira only shares workflow definitions with some permissions.
+func getIssueWorkflow(jiraClient *jira.Client, issue *jira.Issue) (*workflow, error) {
+	var schemes workflowSchemeAssociations
+	err := getJSON(jiraClient,
+		"rest/api/2/workflowscheme/project?projectId="+url.QueryEscape(issue.Fields.Project.ID),
+		&schemes)
+	if err != nil {
+		return nil, err
+	}
+	if len(schemes.Values) == 0 {
+		return nil, fmt.Errorf("no workflow scheme for project %s", issue.Fields.Project.Key)
+	}
+	scheme := schemes.Values[0].WorkflowScheme
+	workflowName, ok := scheme.IssueTypeMappings[issue.Fields.Type.ID]
+	if !ok {
+		workflowName = scheme.DefaultWorkflow
+	}
+
+	var search workflowSearch
+	err = getJSON(jiraClient,
+		"rest/api/2/workflow/search?expand=transitions,statuses&workflowName="+
+			url.QueryEscape(workflowName),
+		&search)
+	if err != nil {
+		return nil, err
+	}
+	if len(search.Values) == 0 {
+		return nil, fmt.Errorf("workflow %s was not found", workflowName)
+	}
+	return &search.Values[0], nil
+}
@@ -0,0 +1,102 @@
+package atlassian
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+// testWorkflow is To Do -> In Progress -> In Review -> Done, with a global
+// transition to Blocked and the initial transition that creates issues in
+// To Do, which like a global transition has no from statuses.
+const testWorkflow = `{
+  "statuses": [
+    {"id": "1", "name": "To Do"},
+    {"id": "2", "name": "In Progress"},
+    {"id": "3", "name": "In Review"},
+    {"id": "4", "name": "Done"},
+    {"id": "5", "name": "Blocked"}
+  ],
+  "transitions": [
+    {"id": "1", "name": "Create", "type": "initial", "from": [], "to": "1"},
+    {"id": "11", "name": "Start", "type": "directed", "from": ["1", "5"], "to": "2"},
+    {"id": "21", "name": "Review", "type": "directed", "from": ["2"], "to": "3"},
+    {"id": "31", "name": "Finish", "type": "directed", "from": ["3"], "to": "4"},
+    {"id": "41", "name": "Reopen", "type": "directed", "from": ["4"], "to": "2"},
+    {"id": "51", "name": "Stop To Do", "type": "directed", "from": ["2"], "to": "1"},
+    {"id": "61", "name": "Block", "type": "global", "from": [], "to": "5"}
+  ]
+}`
+
+func TestShortestPath(t *testing.T) {
+	var wf workflow
+	if err := json.Unmarshal([]byte(testWorkflow), &wf); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name          string
+		start, target string
+		maxHops       int
+		want          []string
+	}{
+		{"one hop", "1", "2", 5, []string{"In Progress"}},
+		{"several hops", "1", "4", 5, []string{"In Progress", "In Review", "Done"}},
+		{"global transition", "4", "5", 5, []string{"Blocked"}},
+		{"not through the initial transition", "4", "1", 5, []string{"In Progress", "To Do"}},
+		{"initial status needs a real path", "3", "1", 1, nil},
+		{"already there", "2", "2", 5, []string{}},
+		{"too far", "1", "4", 2, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := wf.shortestPath(tt.start, tt.target, tt.maxHops)
+			var got []string
+			if path != nil {
+				got = []string{}
+				for _, s := range path {
+					got = append(got, s.Name)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("shortestPath(%s, %s) = %q, want %q", tt.start, tt.target, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatusPath(t *testing.T) {
+	var wf workflow
+	if err := json.Unmarshal([]byte(testWorkflow), &wf); err != nil {
+		t.Fatal(err)
+	}
+	issue := &jira.Issue{Key: "TEAM-1", Fields: &jira.IssueFields{
+		Status: &jira.Status{ID: "2", Name: "In Progress"},
+	}}
+	tests := []struct {
+		name, status string
+		want         []string
+		err          string
+	}{
+		{"path", "done", []string{"In Review", "Done"}, ""},
+		{"current status", "progess", nil, "already in status In Progress"},
+		{"no such status", "xyzzy", nil, "does not clearly match"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := wf.statusPath(issue, tt.status, 5)
+			var got []string
+			for _, s := range path {
+				got = append(got, s.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("statusPath(%q) = %q, want %q", tt.status, got, tt.want)
+			}
+			if tt.err == "" && err != nil || tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+				t.Errorf("statusPath(%q) error = %v, want %q", tt.status, err, tt.err)
+			}
+		})
+	}
+}
@@ -12,6 +12,7 @@ import (
 )
 
 // HeaderRoundTripper is a client middleware for adding headers on every request.
+// Headers a request sets itself, like the Content-Type of an upload, are kept.
 type HeaderRoundTripper struct {
 	next   http.RoundTripper
 	Header http.Header
@@ -30,7 +31,9 @@ func NewHeaderRoundTripper(next http.RoundTripper, header http.Header) *HeaderRo
 func (rt *HeaderRoundTripper) RoundTrip(req *http.Request) (resp *http.Response, err error) {
 	if rt.Header != nil {
 		for k, v := range rt.Header {
-			req.Header[k] = v
+			if _, ok := req.Header[k]; !ok {
+				req.Header[k] = v
+			}
 		}
 	}
 	return rt.next.RoundTrip(req)
@@ -58,10 +61,19 @@ func (rt *HeaderRoundTripper) SetHeader(key, value string) {
 // that adds basic auth header and json
 // as well as a generous 60-second timeout.
 func NewBasicAuthHTTPClient(user, token string) *http.Client {
+	return newBasicAuthHTTPClient(user, token, NewLoggingRoundTripper(http.DefaultTransport, os.Stdout))
+}
+
+// NewQuietBasicAuthHTTPClient is NewBasicAuthHTTPClient without logging
+// failed requests, for requests where failing is an expected answer.
+func NewQuietBasicAuthHTTPClient(user, token string) *http.Client {
+	return newBasicAuthHTTPClient(user, token, http.DefaultTransport)
+}
+
+func newBasicAuthHTTPClient(user, token string, rt http.RoundTripper) *http.Client {
 	header := make(http.Header)
 	header.Set("Content-Type", "application/json; charset=utf-8")
 	header.Set("Accept", "application/json; charset=utf-8")
-	rt := NewLoggingRoundTripper(http.DefaultTransport, os.Stdout)
 	hrt := NewHeaderRoundTripper(rt, header)
 	hrt.BasicAuth(user, token)
 
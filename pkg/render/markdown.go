@@ -0,0 +1,315 @@
+// Package render styles Github Markdown for reading in a terminal.
+package render
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/StevenACoffman/jt/pkg/colors"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/reflow/ansi"
+	"github.com/muesli/reflow/wordwrap"
+)
+
+var (
+	accent = lipgloss.AdaptiveColor{
+		Light: colors.ANSIBlue.String(),
+		Dark:  colors.ANSIBrightBlue.String(),
+	}
+	subtle = lipgloss.AdaptiveColor{Light: "244", Dark: "240"} // #808080 or #585858
+
+	h1Style      = lipgloss.NewStyle().Bold(true).Underline(true).Foreground(accent)
+	headingStyle = lipgloss.NewStyle().Bold(true).Foreground(accent)
+	boldStyle    = lipgloss.NewStyle().Bold(true)
+	italicStyle  = lipgloss.NewStyle().Italic(true)
+	strikeStyle  = lipgloss.NewStyle().Strikethrough(true)
+	codeStyle    = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{
+		Light: colors.ANSIMagenta.String(),
+		Dark:  colors.ANSIBrightMagenta.String(),
+	})
+	linkStyle      = lipgloss.NewStyle().Underline(true).Foreground(accent)
+	subtleStyle    = lipgloss.NewStyle().Foreground(subtle)
+	quoteStyle     = lipgloss.NewStyle().Italic(true)
+	tableHeadStyle = lipgloss.NewStyle().Bold(true)
+
+	fenceRe     = regexp.MustCompile("^\\s*```")
+	headingRe   = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	listRe      = regexp.MustCompile(`^(\s*)([*+-]|\d+\.)\s+(.*)$`)
+	quoteRe     = regexp.MustCompile(`^\s*>\s?(.*)$`)
+	tableRowRe  = regexp.MustCompile(`^\s*\|.*\|\s*$`)
+	tableRuleRe = regexp.MustCompile(`^\s*\|(\s*:?-{3,}:?\s*\|)+\s*$`)
+	ruleRe      = regexp.MustCompile(`^\s*([-*_])(\s*[-*_]){2,}\s*$`)
+
+	inlineRules = []struct {
+		re    *regexp.Regexp
+		style func(groups []string) string
+	}{
+		{ // Code first, so nothing inside it gets styled
+			re:    regexp.MustCompile("`([^`]+)`"),
+			style: func(g []string) string { return codeStyle.Render(g[1]) },
+		},
+		{ // Backslash escapes, so what they escape is shown as it is
+			re:    regexp.MustCompile("\\\\([!-/:-@\\[-`{-~])"),
+			style: func(g []string) string { return g[1] },
+		},
+		{ // Images
+			re: regexp.MustCompile(`!\[([^\]]*)\]\(([^)]+)\)`),
+			style: func(g []string) string {
+				return subtleStyle.Render("[image: ") + linkStyle.Render(g[2]) + subtleStyle.Render("]")
+			},
+		},
+		{ // Named links
+			re: regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`),
+			style: func(g []string) string {
+				return linkStyle.Render(g[1]) + subtleStyle.Render(" ("+g[2]+")")
+			},
+		},
+		{ // Autolinks
+			re:    regexp.MustCompile(`<(https?://[^>]+)>`),
+			style: func(g []string) string { return linkStyle.Render(g[1]) },
+		},
+		{
+			re:    regexp.MustCompile(`\*\*([^*]+)\*\*`),
+			style: func(g []string) string { return boldStyle.Render(g[1]) },
+		},
+		{
+			re:    regexp.MustCompile(`\*([^*\s][^*]*)\*`),
+			style: func(g []string) string { return italicStyle.Render(g[1]) },
+		},
+		{
+			re:    regexp.MustCompile(`~~([^~]+)~~`),
+			style: func(g []string) string { return strikeStyle.Render(g[1]) },
+		},
+	}
+)
+
+// Markdown renders Github Markdown with terminal styles, word wrapped to width.
+// It handles the Markdown that JiraToMD produces: headings, emphasis, code,
+// links, lists, block quotes and tables.
+func Markdown(md string, width int) string {
+	if width <= 0 {
+		width = 80
+	}
+	lines := strings.Split(md, "\n")
+	var out []string
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case fenceRe.MatchString(line):
+			// code blocks are shown as they are, never wrapped
+			lang := strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "`"))
+			if lang != "" {
+				out = append(out, subtleStyle.Render("  "+lang))
+			}
+			for i++; i < len(lines) && !fenceRe.MatchString(lines[i]); i++ {
+				out = append(out, subtleStyle.Render("  │ ")+codeStyle.Render(lines[i]))
+			}
+		case tableRowRe.MatchString(line):
+			var rows []string
+			for ; i < len(lines) && tableRowRe.MatchString(lines[i]); i++ {
+				rows = append(rows, lines[i])
+			}
+			i--
+			out = append(out, renderTable(rows, width)...)
+		case headingRe.MatchString(line):
+			groups := headingRe.FindStringSubmatch(line)
+			style := headingStyle
+			if len(groups[1]) == 1 {
+				style = h1Style
+			}
+			out = append(out, "", wrap(style.Render(inline(groups[2])), width, "", ""))
+		case ruleRe.MatchString(line):
+			out = append(out, subtleStyle.Render(strings.Repeat("─", width)))
+		case listRe.MatchString(line):
+			groups := listRe.FindStringSubmatch(line)
+			indent := strings.Repeat(" ", len(groups[1]))
+			bullet := "• "
+			if groups[2][0] >= '0' && groups[2][0] <= '9' {
+				bullet = groups[2] + " "
+			}
+			hanging := indent + strings.Repeat(" ", ansi.PrintableRuneWidth(bullet))
+			out = append(out, wrap(inline(groups[3]), width, indent+subtleStyle.Render(bullet), hanging))
+		case quoteRe.MatchString(line):
+			bar := subtleStyle.Render("│ ")
+			out = append(out, wrap(quoteStyle.Render(inline(quoteRe.FindStringSubmatch(line)[1])), width, bar, bar))
+		default:
+			out = append(out, wrap(inline(line), width, "", ""))
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// inline styles emphasis, code and links within a line
+func inline(text string) string {
+	// Styled spans are swapped for placeholders as we go, so later rules
+	// can't match inside the escape codes or text of earlier ones.
+	var spans []string
+	for _, rule := range inlineRules {
+		rule := rule
+		text = rule.re.ReplaceAllStringFunc(text, func(match string) string {
+			spans = append(spans, rule.style(rule.re.FindStringSubmatch(match)))
+			return placeholder(len(spans) - 1)
+		})
+	}
+	// later spans can contain earlier placeholders, so restore newest first
+	for i := len(spans) - 1; i >= 0; i-- {
+		text = strings.Replace(text, placeholder(i), spans[i], 1)
+	}
+	return text
+}
+
+func placeholder(i int) string {
+	return "\x00" + string(rune('A'+i%26)) + strings.Repeat("\x01", i/26) + "\x00"
+}
+
+// wrap word wraps text, starting the first line with first and the rest with rest
+func wrap(text string, width int, first, rest string) string {
+	limit := width - ansi.PrintableRuneWidth(first)
+	if limit < 20 {
+		limit = 20
+	}
+	wrapped := strings.Split(wordwrap.String(text, limit), "\n")
+	for i := range wrapped {
+		if i == 0 {
+			wrapped[i] = first + wrapped[i]
+		} else {
+			wrapped[i] = rest + wrapped[i]
+		}
+	}
+	return strings.Join(wrapped, "\n")
+}
+
+// renderTable lines up a Markdown table's columns, shrinking them to fit width
+func renderTable(rows []string, width int) []string {
+	var cells [][]string
+	hasHeader := false
+	for i, row := range rows {
+		if tableRuleRe.MatchString(row) {
+			hasHeader = i == 1
+			continue
+		}
+		var rowCells []string
+		for _, cell := range splitRow(row) {
+			rowCells = append(rowCells, inline(strings.TrimSpace(cell)))
+		}
+		cells = append(cells, rowCells)
+	}
+
+	var widths []int
+	for _, row := range cells {
+		for c, cell := range row {
+			if c >= len(widths) {
+				widths = append(widths, 0)
+			}
+			if w := ansi.PrintableRuneWidth(cell); w > widths[c] {
+				widths[c] = w
+			}
+		}
+	}
+	// shrink the widest column until it all fits
+	for total(widths)+3*len(widths)+1 > width {
+		widest := 0
+		for c := range widths {
+			if widths[c] > widths[widest] {
+				widest = c
+			}
+		}
+		if widths[widest] <= 8 {
+			break
+		}
+		widths[widest]--
+	}
+
+	bar := subtleStyle.Render("│")
+	var out []string
+	for r, row := range cells {
+		var rendered []string
+		for c := range widths {
+			cell := ""
+			if c < len(row) {
+				cell = row[c]
+			}
+			style := lipgloss.NewStyle().Width(widths[c])
+			if r == 0 && hasHeader {
+				style = tableHeadStyle.Copy().Width(widths[c])
+			}
+			rendered = append(rendered, style.Render(cell))
+		}
+		out = append(out, joinCells(rendered, bar)...)
+		if r == 0 && hasHeader {
+			var rule []string
+			for _, w := range widths {
+				rule = append(rule, strings.Repeat("─", w+2))
+			}
+			out = append(out, subtleStyle.Render("├"+strings.Join(rule, "┼")+"┤"))
+		}
+	}
+	return out
+}
+
+// splitRow splits a table row into its cells on the bars that aren't
+// escaped, so a cell can hold a bar written as \|
+func splitRow(row string) []string {
+	row = strings.TrimSpace(row)
+	row = strings.TrimPrefix(row, "|")
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(row); i++ {
+		switch {
+		case row[i] == '\\' && i+1 < len(row) && row[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case row[i] == '\\' && i+1 < len(row):
+			// other escapes are for inline to show
+			cell.WriteString(row[i : i+2])
+			i++
+		case row[i] == '|':
+			cells = append(cells, cell.String())
+			cell.Reset()
+		default:
+			cell.WriteByte(row[i])
+		}
+	}
+	if rest := strings.TrimSpace(cell.String()); rest != "" {
+		cells = append(cells, rest)
+	}
+	return cells
+}
+
+// joinCells puts cells side by side between bars, allowing for cells
+// that wrapped onto several lines.
+func joinCells(cells []string, bar string) []string {
+	height := 1
+	split := make([][]string, len(cells))
+	for i, cell := range cells {
+		split[i] = strings.Split(cell, "\n")
+		if len(split[i]) > height {
+			height = len(split[i])
+		}
+	}
+	lines := make([]string, height)
+	for l := range lines {
+		var b strings.Builder
+		b.WriteString(bar)
+		for i := range split {
+			cell := ""
+			if l < len(split[i]) {
+				cell = split[i][l]
+			} else if len(split[i]) > 0 {
+				cell = strings.Repeat(" ", ansi.PrintableRuneWidth(split[i][0]))
+			}
+			b.WriteString(" " + cell + " " + bar)
+		}
+		lines[l] = b.String()
+	}
+	return lines
+}
+
+func total(values []int) int {
+	sum := 0
+	for _, v := range values {
+		sum += v
+	}
+	return sum
+}
@@ -0,0 +1,60 @@
+package render
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var ansiRe = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// plain renders md and drops the styles, leaving the text as laid out
+func plain(md string, width int) string {
+	return ansiRe.ReplaceAllString(Markdown(md, width), "")
+}
+
+func TestSplitRow(t *testing.T) {
+	tests := []struct {
+		row  string
+		want []string
+	}{
+		{"| a | b |", []string{" a ", " b "}},
+		{`| a \| b | c |`, []string{" a | b ", " c "}},
+		{`| \*x\* | y |`, []string{` \*x\* `, " y "}},
+		{"| a |  |", []string{" a ", "  "}},
+	}
+	for _, tt := range tests {
+		if got := splitRow(tt.row); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitRow(%q) = %q, want %q", tt.row, got, tt.want)
+		}
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	tests := []struct {
+		name, md, want string
+	}{
+		{"escapes", `Fix \*all\* the \[things\]`, "Fix *all* the [things]"},
+		{"escapes in links", `[TEAM-1](https://x/TEAM-1) (Fix \*all\*)`, "TEAM-1 (https://x/TEAM-1) (Fix *all*)"},
+		{"escapes in code", "`a\\*b`", "a\\*b"},
+		{"emphasis", "**bold** and *italic*", "bold and italic"},
+		{
+			"table with escaped bars",
+			"| key | summary |\n| --- | --- |\n| TEAM-1 | a \\| b |",
+			"│ key    │ summary │\n├────────┼─────────┤\n│ TEAM-1 │ a | b   │",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := plain(tt.md, 80)
+			lines := strings.Split(got, "\n")
+			for i := range lines {
+				lines[i] = strings.TrimRight(lines[i], " ")
+			}
+			if got = strings.Join(lines, "\n"); got != tt.want {
+				t.Errorf("Markdown(%q) = %q, want %q", tt.md, got, tt.want)
+			}
+		})
+	}
+}